@@ -0,0 +1,163 @@
+package bench
+
+import (
+    "fmt"
+    "runtime"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// PoliticasVinculo lista as politicas aceitas por --bind: "close" ocupa as
+// CPUs em ordem, "spread" distribui os workers uniformemente pela lista e
+// "none" deixa o escalonador livre.
+var PoliticasVinculo = []string{"close", "spread", "none"}
+
+type VinculoWorker struct {
+    Worker int `json:"worker"`
+    Cpu    int `json:"cpu"`
+}
+
+// RelatorioAfinidade descreve a afinidade aplicada durante a execucao: a
+// mascara do processo, a politica e em qual CPU cada worker foi fixado.
+type RelatorioAfinidade struct {
+    Cpus     string          `json:"cpus"`
+    Politica string          `json:"politica"`
+    Vinculos []VinculoWorker `json:"vinculos,omitempty"`
+}
+
+var estadoAfinidade struct {
+    sync.Mutex
+    configurada bool
+    cpus        []int
+    politica    string
+    vinculos    map[int]int
+}
+
+// InterpretarListaCpus aceita listas como "0-11", "0,2,4" ou "0-22:2".
+func InterpretarListaCpus(texto string) ([]int, error) {
+    return interpretarListaInteiros(texto, 0, "cpu")
+}
+
+// ConfigurarAfinidade restringe todas as threads do processo a cpus (ou
+// mantem a mascara atual quando cpus e vazia) e define a politica usada por
+// VincularWorker. Threads criadas depois herdam a mascara do processo.
+func ConfigurarAfinidade(cpus []int, politica string) error {
+    if !politicaValida(politica) {
+        return fmt.Errorf("politica de vinculo desconhecida %q (use %s)", politica, strings.Join(PoliticasVinculo, ", "))
+    }
+    if len(cpus) == 0 {
+        atuais, err := InterpretarListaCpus(lerCampoStatus("Cpus_allowed_list"))
+        if err != nil {
+            return fmt.Errorf("nao foi possivel ler a mascara de afinidade atual: %w", err)
+        }
+        cpus = atuais
+    } else if err := definirAfinidadeProcesso(cpus); err != nil {
+        return fmt.Errorf("nao foi possivel aplicar a afinidade %s: %w", FormatarListaCpus(cpus), err)
+    }
+    estadoAfinidade.Lock()
+    defer estadoAfinidade.Unlock()
+    estadoAfinidade.configurada = true
+    estadoAfinidade.cpus = append([]int(nil), cpus...)
+    estadoAfinidade.politica = politica
+    estadoAfinidade.vinculos = map[int]int{}
+    return nil
+}
+
+// VinculoAtivo informa se --bind fixa os workers em CPUs. Kernels que criam
+// goroutines sob demanda usam isso para so manter workers fixos quando ha
+// vinculo, sem mudar a carga medida nas execucoes sem --bind.
+func VinculoAtivo() bool {
+    estadoAfinidade.Lock()
+    defer estadoAfinidade.Unlock()
+    return estadoAfinidade.configurada && estadoAfinidade.politica != "none" && len(estadoAfinidade.cpus) > 0
+}
+
+// VincularWorker prende a goroutine do worker indice (de total) a sua thread
+// e fixa essa thread na CPU escolhida pela politica configurada. A funcao
+// devolvida restaura a mascara do processo e libera a thread; deve ser chamada
+// quando o worker termina. Sem politica de vinculo, nada e feito.
+func VincularWorker(indice, total int) func() {
+    estadoAfinidade.Lock()
+    if !estadoAfinidade.configurada || estadoAfinidade.politica == "none" || len(estadoAfinidade.cpus) == 0 {
+        estadoAfinidade.Unlock()
+        return func() {}
+    }
+    cpus := estadoAfinidade.cpus
+    cpu := escolherCpu(cpus, estadoAfinidade.politica, indice, total)
+    estadoAfinidade.vinculos[indice] = cpu
+    estadoAfinidade.Unlock()
+
+    runtime.LockOSThread()
+    if err := definirAfinidadeThread(0, []int{cpu}); err != nil {
+        // Sem permissao para fixar a thread o worker segue na mascara do
+        // processo; o vinculo nao e reportado.
+        estadoAfinidade.Lock()
+        delete(estadoAfinidade.vinculos, indice)
+        estadoAfinidade.Unlock()
+        runtime.UnlockOSThread()
+        return func() {}
+    }
+    return func() {
+        _ = definirAfinidadeThread(0, cpus)
+        runtime.UnlockOSThread()
+    }
+}
+
+// escolherCpu mapeia o worker indice de total para uma CPU da lista.
+func escolherCpu(cpus []int, politica string, indice, total int) int {
+    if politica == "spread" && total > 0 {
+        return cpus[(indice*len(cpus)/total)%len(cpus)]
+    }
+    return cpus[indice%len(cpus)]
+}
+
+// relatorioAfinidade devolve a afinidade aplicada desde a ultima coleta e
+// limpa os vinculos registrados, ou nil quando --cpus/--bind nao foram usados.
+func relatorioAfinidade() *RelatorioAfinidade {
+    estadoAfinidade.Lock()
+    defer estadoAfinidade.Unlock()
+    if !estadoAfinidade.configurada {
+        return nil
+    }
+    relatorio := &RelatorioAfinidade{
+        Cpus:     FormatarListaCpus(estadoAfinidade.cpus),
+        Politica: estadoAfinidade.politica,
+    }
+    for worker, cpu := range estadoAfinidade.vinculos {
+        relatorio.Vinculos = append(relatorio.Vinculos, VinculoWorker{Worker: worker, Cpu: cpu})
+    }
+    sort.Slice(relatorio.Vinculos, func(i, j int) bool { return relatorio.Vinculos[i].Worker < relatorio.Vinculos[j].Worker })
+    estadoAfinidade.vinculos = map[int]int{}
+    return relatorio
+}
+
+// FormatarListaCpus compacta cpus no formato de Cpus_allowed_list ("0-3,6").
+func FormatarListaCpus(cpus []int) string {
+    ordenadas := append([]int(nil), cpus...)
+    sort.Ints(ordenadas)
+    var partes []string
+    for i := 0; i < len(ordenadas); {
+        j := i
+        for j+1 < len(ordenadas) && ordenadas[j+1] == ordenadas[j]+1 {
+            j++
+        }
+        if j == i {
+            partes = append(partes, strconv.Itoa(ordenadas[i]))
+        } else {
+            partes = append(partes, strconv.Itoa(ordenadas[i])+"-"+strconv.Itoa(ordenadas[j]))
+        }
+        i = j + 1
+    }
+    return strings.Join(partes, ",")
+}
+
+func politicaValida(politica string) bool {
+    for _, p := range PoliticasVinculo {
+        if p == politica {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,58 @@
+package bench
+
+import (
+    "fmt"
+    "os"
+    "strconv"
+    "syscall"
+    "unsafe"
+)
+
+// maximoCpus e o tamanho da mascara passada ao kernel (cpu_set_t de 1024 bits).
+const maximoCpus = 1024
+
+// definirAfinidadeThread chama sched_setaffinity para a thread tid (0 e a
+// thread atual).
+func definirAfinidadeThread(tid int, cpus []int) error {
+    var mascara [maximoCpus / 64]uint64
+    for _, cpu := range cpus {
+        if cpu < 0 || cpu >= maximoCpus {
+            return fmt.Errorf("cpu %d fora do intervalo suportado", cpu)
+        }
+        mascara[cpu/64] |= 1 << (uint(cpu) % 64)
+    }
+    _, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, uintptr(tid), unsafe.Sizeof(mascara), uintptr(unsafe.Pointer(&mascara[0])))
+    if errno != 0 {
+        return errno
+    }
+    return nil
+}
+
+// definirAfinidadeProcesso aplica a mascara a todas as threads listadas em
+// /proc/self/task. Repete a varredura ate nao surgir thread nova, ja que o
+// runtime pode criar threads enquanto a lista e percorrida.
+func definirAfinidadeProcesso(cpus []int) error {
+    aplicadas := map[int]bool{}
+    for tentativa := 0; tentativa < 5; tentativa++ {
+        entradas, err := os.ReadDir("/proc/self/task")
+        if err != nil {
+            return err
+        }
+        novas := 0
+        for _, entrada := range entradas {
+            tid, err := strconv.Atoi(entrada.Name())
+            if err != nil || aplicadas[tid] {
+                continue
+            }
+            if err := definirAfinidadeThread(tid, cpus); err != nil && err != syscall.ESRCH {
+                return err
+            }
+            aplicadas[tid] = true
+            novas++
+        }
+        if novas == 0 {
+            return nil
+        }
+    }
+    return nil
+}
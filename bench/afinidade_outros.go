@@ -0,0 +1,15 @@
+//go:build !linux
+
+package bench
+
+import "errors"
+
+var errAfinidadeIndisponivel = errors.New("afinidade de CPU so e suportada no Linux")
+
+func definirAfinidadeThread(tid int, cpus []int) error {
+    return errAfinidadeIndisponivel
+}
+
+func definirAfinidadeProcesso(cpus []int) error {
+    return errAfinidadeIndisponivel
+}
@@ -0,0 +1,159 @@
+package bench
+
+import (
+    "math"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "runtime"
+    "runtime/debug"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+type Ambiente struct {
+    VersaoGo             string `json:"versao_go"`
+    SistemaOperacional   string `json:"goos"`
+    Arquitetura          string `json:"goarch"`
+    ModeloCpu            string `json:"modelo_cpu"`
+    NucleosFisicos       int    `json:"nucleos_fisicos"`
+    ThreadsLogicas       int    `json:"threads_logicas"`
+    VersaoKernel         string `json:"versao_kernel"`
+    GovernadorFrequencia string `json:"governador_frequencia"`
+    MascaraAfinidade     string `json:"mascara_afinidade"`
+    GOMAXPROCS           int    `json:"gomaxprocs"`
+    GOGC                 string `json:"gogc"`
+    GOMEMLIMIT           string `json:"gomemlimit"`
+    Hostname             string `json:"hostname"`
+    CommitGit            string `json:"commit_git"`
+}
+
+var (
+    ambienteFixo         Ambiente
+    carregarAmbienteFixo sync.Once
+)
+
+// ColetarAmbiente descreve a maquina e o runtime onde o benchmark rodou. Os
+// dados que nao mudam durante o processo sao lidos uma unica vez; GOMAXPROCS e
+// a mascara de afinidade sao relidos a cada chamada.
+func ColetarAmbiente() Ambiente {
+    carregarAmbienteFixo.Do(func() {
+        ambienteFixo = Ambiente{
+            VersaoGo:             runtime.Version(),
+            SistemaOperacional:   runtime.GOOS,
+            Arquitetura:          runtime.GOARCH,
+            VersaoKernel:         lerPrimeiraLinha("/proc/sys/kernel/osrelease"),
+            GovernadorFrequencia: lerPrimeiraLinha("/sys/devices/system/cpu/cpu0/cpufreq/scaling_governor"),
+            GOGC:                 ObterStringEnv("GOGC", "100"),
+            CommitGit:            commitGit(),
+        }
+        ambienteFixo.ModeloCpu, ambienteFixo.NucleosFisicos, ambienteFixo.ThreadsLogicas = lerCpuInfo()
+        ambienteFixo.Hostname, _ = os.Hostname()
+        ambienteFixo.GOMEMLIMIT = "off"
+        if limite := debug.SetMemoryLimit(-1); limite != math.MaxInt64 {
+            ambienteFixo.GOMEMLIMIT = strconv.FormatInt(limite, 10)
+        }
+    })
+    ambiente := ambienteFixo
+    ambiente.GOMAXPROCS = runtime.GOMAXPROCS(0)
+    ambiente.MascaraAfinidade = lerCampoStatus("Cpus_allowed_list")
+    return ambiente
+}
+
+func lerPrimeiraLinha(caminho string) string {
+    dados, err := os.ReadFile(caminho)
+    if err != nil {
+        return ""
+    }
+    linha, _, _ := strings.Cut(string(dados), "\n")
+    return strings.TrimSpace(linha)
+}
+
+func lerCampoStatus(nome string) string {
+    status, err := os.ReadFile("/proc/self/status")
+    if err != nil {
+        return ""
+    }
+    for _, linha := range strings.Split(string(status), "\n") {
+        if valor, ok := strings.CutPrefix(linha, nome+":"); ok {
+            return strings.TrimSpace(valor)
+        }
+    }
+    return ""
+}
+
+// lerCpuInfo conta os nucleos fisicos pelos pares distintos (physical id,
+// core id) de /proc/cpuinfo; sem essas chaves, cada processador conta como um
+// nucleo.
+func lerCpuInfo() (string, int, int) {
+    dados, err := os.ReadFile("/proc/cpuinfo")
+    if err != nil {
+        return "", 0, runtime.NumCPU()
+    }
+    modelo := ""
+    processadores := 0
+    nucleos := map[string]bool{}
+    idFisico := ""
+    for _, linha := range strings.Split(string(dados), "\n") {
+        chave, valor, ok := strings.Cut(linha, ":")
+        if !ok {
+            continue
+        }
+        chave, valor = strings.TrimSpace(chave), strings.TrimSpace(valor)
+        switch chave {
+        case "processor":
+            processadores++
+            idFisico = ""
+        case "model name", "Processor", "cpu model":
+            if modelo == "" {
+                modelo = valor
+            }
+        case "physical id":
+            idFisico = valor
+        case "core id":
+            nucleos[idFisico+"/"+valor] = true
+        }
+    }
+    nucleosFisicos := len(nucleos)
+    if nucleosFisicos == 0 {
+        nucleosFisicos = processadores
+    }
+    return modelo, nucleosFisicos, processadores
+}
+
+// commitGit usa a revisao gravada pelo go build e, na falta dela (go run), o
+// git da arvore onde o pacote foi compilado. Alteracoes locais viram "-dirty".
+func commitGit() string {
+    if informacoes, ok := debug.ReadBuildInfo(); ok {
+        revisao, modificado := "", false
+        for _, configuracao := range informacoes.Settings {
+            switch configuracao.Key {
+            case "vcs.revision":
+                revisao = configuracao.Value
+            case "vcs.modified":
+                modificado = configuracao.Value == "true"
+            }
+        }
+        if revisao != "" {
+            if modificado {
+                revisao += "-dirty"
+            }
+            return revisao
+        }
+    }
+    _, arquivo, _, ok := runtime.Caller(0)
+    if !ok {
+        return ""
+    }
+    diretorio := filepath.Dir(arquivo)
+    saida, err := exec.Command("git", "-C", diretorio, "rev-parse", "HEAD").Output()
+    if err != nil {
+        return ""
+    }
+    revisao := strings.TrimSpace(string(saida))
+    if err := exec.Command("git", "-C", diretorio, "diff", "--quiet", "HEAD").Run(); err != nil {
+        revisao += "-dirty"
+    }
+    return revisao
+}
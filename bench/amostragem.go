@@ -0,0 +1,142 @@
+package bench
+
+import (
+    "encoding/json"
+    "os"
+    "runtime"
+    "strconv"
+    "sync"
+    "time"
+)
+
+// PontoAmostragem e uma leitura do amostrador de fundo. Instante e contado a
+// partir do inicio da regiao medida e o uso de CPU se refere ao intervalo
+// desde o ponto anterior.
+type PontoAmostragem struct {
+    InstanteMs       float64 `json:"instante_ms"`
+    CpuPct           float64 `json:"percentual_uso_cpu"`
+    RSSMb            float64 `json:"memoria_rss_mb"`
+    Goroutines       int     `json:"goroutines"`
+    ThreadsSO        int     `json:"threads_so"`
+    ProfundidadeFila *int    `json:"profundidade_fila,omitempty"`
+}
+
+// SerieAmostragem descreve a serie temporal de uma execucao. Os pontos vao no
+// proprio resultado ou, quando arquivo e informado, para esse arquivo JSONL
+// (uma linha por ponto identificada por execucao).
+type SerieAmostragem struct {
+    IntervaloMs float64           `json:"intervalo_ms"`
+    Execucao    int               `json:"execucao"`
+    TotalPontos int               `json:"total_pontos"`
+    Arquivo     string            `json:"arquivo,omitempty"`
+    Erro        string            `json:"erro,omitempty"`
+    Pontos      []PontoAmostragem `json:"pontos,omitempty"`
+}
+
+type linhaAmostragem struct {
+    Problema string `json:"nome_problema"`
+    Tamanho  int    `json:"tamanho_instancia"`
+    Threads  int    `json:"quantidade_threads"`
+    Execucao int    `json:"execucao"`
+    PontoAmostragem
+}
+
+var (
+    profundidadeFila   func() int
+    profundidadeFilaMu sync.Mutex
+)
+
+// RegistrarFila informa ao amostrador como ler a ocupacao da fila do
+// benchmark. Deve ser chamada antes de CapturarAmostraRecursos; o registro vale
+// ate o fim da regiao medida.
+func RegistrarFila(profundidade func() int) {
+    profundidadeFilaMu.Lock()
+    profundidadeFila = profundidade
+    profundidadeFilaMu.Unlock()
+}
+
+type amostrador struct {
+    intervalo time.Duration
+    parar     chan struct{}
+    concluido chan struct{}
+    pontos    []PontoAmostragem
+}
+
+// iniciarAmostrador dispara a gorrotina que le os recursos a cada intervalo.
+func iniciarAmostrador(intervalo time.Duration, inicio time.Time) *amostrador {
+    profundidadeFilaMu.Lock()
+    fila := profundidadeFila
+    profundidadeFilaMu.Unlock()
+    a := &amostrador{intervalo: intervalo, parar: make(chan struct{}), concluido: make(chan struct{})}
+    go func() {
+        defer close(a.concluido)
+        relogio := time.NewTicker(intervalo)
+        defer relogio.Stop()
+        anteriorParede, anteriorCpu := inicio, TempoCpuEmMs()
+        for {
+            select {
+            case <-a.parar:
+                return
+            case agora := <-relogio.C:
+                cpu := TempoCpuEmMs()
+                ponto := PontoAmostragem{
+                    InstanteMs: agora.Sub(inicio).Seconds() * 1000.0,
+                    RSSMb:      MemoriaAtualEmMb(),
+                    Goroutines: runtime.NumGoroutine(),
+                }
+                if decorrido := agora.Sub(anteriorParede).Seconds() * 1000.0; decorrido > 0 {
+                    ponto.CpuPct = (cpu - anteriorCpu) / decorrido * 100.0
+                }
+                ponto.ThreadsSO, _ = strconv.Atoi(lerCampoStatus("Threads"))
+                if fila != nil {
+                    profundidade := fila()
+                    ponto.ProfundidadeFila = &profundidade
+                }
+                a.pontos = append(a.pontos, ponto)
+                anteriorParede, anteriorCpu = agora, cpu
+            }
+        }
+    }()
+    return a
+}
+
+// encerrar para o amostrador e monta a serie. Com opcoesColeta.ArquivoAmostragem
+// os pontos sao acrescentados ao arquivo em vez de ficarem no resultado.
+func (a *amostrador) encerrar(problema string, tamanho, threads, execucao int) *SerieAmostragem {
+    RegistrarFila(nil)
+    if a == nil {
+        return nil
+    }
+    close(a.parar)
+    <-a.concluido
+    serie := &SerieAmostragem{
+        IntervaloMs: a.intervalo.Seconds() * 1000.0,
+        Execucao:    execucao,
+        TotalPontos: len(a.pontos),
+    }
+    if opcoesColeta.ArquivoAmostragem == "" {
+        serie.Pontos = a.pontos
+        return serie
+    }
+    serie.Arquivo = opcoesColeta.ArquivoAmostragem
+    if err := acrescentarPontos(serie.Arquivo, problema, tamanho, threads, serie.Execucao, a.pontos); err != nil {
+        serie.Erro = err.Error()
+    }
+    return serie
+}
+
+func acrescentarPontos(caminho, problema string, tamanho, threads, execucao int, pontos []PontoAmostragem) error {
+    arquivo, err := os.OpenFile(caminho, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+    if err != nil {
+        return err
+    }
+    codificador := json.NewEncoder(arquivo)
+    for _, ponto := range pontos {
+        linha := linhaAmostragem{Problema: problema, Tamanho: tamanho, Threads: threads, Execucao: execucao, PontoAmostragem: ponto}
+        if err := codificador.Encode(linha); err != nil {
+            arquivo.Close()
+            return err
+        }
+    }
+    return arquivo.Close()
+}
@@ -0,0 +1,83 @@
+package bench
+
+import (
+    "context"
+    "errors"
+)
+
+// Benchmark e um problema medido pelo benchctl. A mesma instancia pode ser
+// medida varias vezes (repeticoes, aquecimento, varredura): Medir chama
+// Preparar, Executar, Verificar e Encerrar nessa ordem a cada execucao, e so
+// Executar fica dentro da regiao medida.
+type Benchmark interface {
+    // Nome identifica o benchmark em benchctl run e no campo nome_problema.
+    Nome() string
+    Descricao() string
+    // Parametros descreve as flags proprias do benchmark, ligadas aos campos
+    // da configuracao da instancia.
+    Parametros() []Parametro
+    // Preparar gera os dados de entrada e inicia os workers, que devem
+    // aguardar Executar para comecar o trabalho.
+    Preparar(ctx context.Context) error
+    // Executar faz o trabalho medido e informa quanto foi feito. Se ctx for
+    // cancelado deve parar cedo e devolver o trabalho parcial.
+    Executar(ctx context.Context) (Trabalho, error)
+    // Verificar confere o estado final de uma execucao completa.
+    Verificar() error
+    // Encerrar libera o que Preparar alocou; e chamada mesmo quando Preparar
+    // ou Executar falham.
+    Encerrar() error
+}
+
+// Trabalho resume uma execucao medida: a escala efetiva usada pelo kernel e
+// os contadores que vao para o resultado.
+type Trabalho struct {
+    Tamanho   int
+    Threads   int
+    Itens     int64
+    Operacoes int64
+    Iteracoes int64
+}
+
+// Medir executa uma vez as fases de b e devolve as metricas da regiao medida.
+// Erros de Preparar sem classificacao viram erros de preparacao e os de
+// Executar e Verificar, erros de kernel; nesses dois casos as metricas
+// coletadas acompanham o erro. Quando b e Conferivel o resultado traz o
+// checksum da saida. Uma execucao interrompida por ctx nao e verificada nem
+// recebe checksum.
+func Medir(ctx context.Context, b Benchmark) (metricas MetricasBenchmark, err error) {
+    defer func() {
+        if errEncerrar := b.Encerrar(); errEncerrar != nil && err == nil {
+            err = classificarFase(ErroInterno, b, errEncerrar)
+        }
+    }()
+    if err := b.Preparar(ctx); err != nil {
+        return MetricasBenchmark{}, classificarFase(ErroPreparacao, b, err)
+    }
+    amostraInicial := CapturarAmostraRecursos()
+    trabalho, err := b.Executar(ctx)
+    metricas = ColetarMetricas(b.Nome(), trabalho.Tamanho, trabalho.Threads, amostraInicial, trabalho.Itens, trabalho.Operacoes, trabalho.Iteracoes)
+    if err != nil {
+        return metricas, classificarFase(ErroKernel, b, err)
+    }
+    if ctx.Err() != nil {
+        return metricas, nil
+    }
+    if err := b.Verificar(); err != nil {
+        return metricas, classificarFase(ErroKernel, b, err)
+    }
+    if err := registrarChecksum(ctx, b, &metricas); err != nil {
+        return metricas, classificarFase(ErroKernel, b, err)
+    }
+    return metricas, nil
+}
+
+// classificarFase mantem a classificacao de erros que ja sao ErroBenchmark e
+// aplica codigo aos demais.
+func classificarFase(codigo string, b Benchmark, err error) error {
+    var estruturado *ErroBenchmark
+    if errors.As(err, &estruturado) {
+        return err
+    }
+    return NovoErro(codigo, err, map[string]string{"benchmark": b.Nome()})
+}
@@ -0,0 +1,97 @@
+// Package benchtest liga os kernels que implementam bench.Benchmark ao pacote
+// testing: testes de correcao rodam as fases diretamente e os wrappers
+// testing.B permitem medir com go test -bench e comparar com benchstat.
+package benchtest
+
+import (
+    "context"
+    "runtime"
+    "testing"
+
+    "tcc-benchmarks/bench"
+)
+
+// Executar roda Preparar, Executar e Verificar de k e devolve o trabalho
+// informado. Se k for bench.Conferivel, o checksum tambem e conferido com a
+// referencia. Encerrar fica registrado em t.Cleanup, entao o estado final do
+// kernel continua disponivel para o teste conferir.
+func Executar(t testing.TB, k bench.Benchmark) bench.Trabalho {
+    t.Helper()
+    restaurarGomaxprocs(t)
+    ctx := context.Background()
+    t.Cleanup(func() {
+        if err := k.Encerrar(); err != nil {
+            t.Errorf("%s: encerrar: %v", k.Nome(), err)
+        }
+    })
+    if err := k.Preparar(ctx); err != nil {
+        t.Fatalf("%s: preparar: %v", k.Nome(), err)
+    }
+    trabalho, err := k.Executar(ctx)
+    if err != nil {
+        t.Fatalf("%s: executar: %v", k.Nome(), err)
+    }
+    if err := k.Verificar(); err != nil {
+        t.Fatalf("%s: verificar: %v", k.Nome(), err)
+    }
+    if conferivel, ok := k.(bench.Conferivel); ok {
+        referencia, err := conferivel.ChecksumReferencia(ctx)
+        if err != nil {
+            t.Fatalf("%s: checksum de referencia: %v", k.Nome(), err)
+        }
+        if obtido := conferivel.Checksum(); !obtido.Confere(referencia) {
+            t.Fatalf("%s: checksum %s, referencia %s", k.Nome(), obtido.Valor, referencia.Valor)
+        }
+    }
+    return trabalho
+}
+
+// Medir roda b.N execucoes de k cronometrando apenas a fase Executar, como a
+// regiao medida do benchctl; Preparar, Verificar e Encerrar ficam fora do
+// tempo. Os contadores do Trabalho sao informados por operacao (itens/op,
+// operacoes/op, iteracoes/op) para o benchstat.
+func Medir(b *testing.B, k bench.Benchmark) {
+    b.Helper()
+    restaurarGomaxprocs(b)
+    ctx := context.Background()
+    var total bench.Trabalho
+    b.StopTimer()
+    b.ResetTimer()
+    for rodada := 0; rodada < b.N; rodada++ {
+        if err := k.Preparar(ctx); err != nil {
+            k.Encerrar()
+            b.Fatalf("%s: preparar: %v", k.Nome(), err)
+        }
+        b.StartTimer()
+        trabalho, err := k.Executar(ctx)
+        b.StopTimer()
+        if err == nil {
+            err = k.Verificar()
+        }
+        if errEncerrar := k.Encerrar(); err == nil {
+            err = errEncerrar
+        }
+        if err != nil {
+            b.Fatalf("%s: %v", k.Nome(), err)
+        }
+        total.Itens += trabalho.Itens
+        total.Operacoes += trabalho.Operacoes
+        total.Iteracoes += trabalho.Iteracoes
+    }
+    reportarPorOperacao(b, total.Itens, "itens/op")
+    reportarPorOperacao(b, total.Operacoes, "operacoes/op")
+    reportarPorOperacao(b, total.Iteracoes, "iteracoes/op")
+}
+
+func reportarPorOperacao(b *testing.B, total int64, unidade string) {
+    if total > 0 {
+        b.ReportMetric(float64(total)/float64(b.N), unidade)
+    }
+}
+
+// restaurarGomaxprocs desfaz, ao fim do teste, o ajuste de GOMAXPROCS que os
+// kernels fazem em Preparar.
+func restaurarGomaxprocs(t testing.TB) {
+    anterior := runtime.GOMAXPROCS(0)
+    t.Cleanup(func() { runtime.GOMAXPROCS(anterior) })
+}
@@ -0,0 +1,92 @@
+package bench
+
+import (
+    "context"
+    "errors"
+    "math"
+    "strconv"
+)
+
+// Checksum resume deterministicamente a saida de uma execucao completa: para
+// a mesma configuracao, qualquer implementacao correta do kernel produz o
+// mesmo valor.
+type Checksum struct {
+    // Valor e o texto gravado no resultado; inteiros de 64 bits e floats vao
+    // sem perda (strconv com precisao -1).
+    Valor string
+    // Tolerancia e o erro relativo aceito ao comparar checksums de ponto
+    // flutuante; 0 exige valores identicos.
+    Tolerancia float64
+}
+
+// ChecksumInteiro formata um checksum exato.
+func ChecksumInteiro(valor uint64) Checksum {
+    return Checksum{Valor: strconv.FormatUint(valor, 10)}
+}
+
+// ChecksumReal formata um checksum de ponto flutuante comparado com erro
+// relativo tolerancia.
+func ChecksumReal(valor, tolerancia float64) Checksum {
+    return Checksum{Valor: strconv.FormatFloat(valor, 'g', -1, 64), Tolerancia: tolerancia}
+}
+
+// Confere informa se c e referencia representam a mesma saida.
+func (c Checksum) Confere(referencia Checksum) bool {
+    if c.Valor == referencia.Valor {
+        return true
+    }
+    tolerancia := math.Max(c.Tolerancia, referencia.Tolerancia)
+    if tolerancia == 0 {
+        return false
+    }
+    obtido, errObtido := strconv.ParseFloat(c.Valor, 64)
+    esperado, errEsperado := strconv.ParseFloat(referencia.Valor, 64)
+    if errObtido != nil || errEsperado != nil {
+        return false
+    }
+    return math.Abs(obtido-esperado) <= tolerancia*math.Max(1, math.Abs(esperado))
+}
+
+// Conferivel e implementado pelos benchmarks que resumem sua saida num
+// Checksum. Os dois metodos sao chamados por Medir depois de Verificar e
+// antes de Encerrar, fora da regiao medida.
+type Conferivel interface {
+    // Checksum resume a saida da execucao que acabou de terminar.
+    Checksum() Checksum
+    // ChecksumReferencia recalcula o checksum esperado com uma implementacao
+    // sequencial, independente do kernel paralelo. Se ctx for cancelado
+    // devolve ctx.Err().
+    ChecksumReferencia(ctx context.Context) (Checksum, error)
+}
+
+// registrarChecksum grava em metricas o checksum de b e, com
+// opcoesColeta.ConferirChecksum, confere-o com a referencia. Uma divergencia
+// e erro de kernel; a referencia interrompida por ctx deixa o resultado sem
+// checksum_referencia.
+func registrarChecksum(ctx context.Context, b Benchmark, metricas *MetricasBenchmark) error {
+    conferivel, ok := b.(Conferivel)
+    if !ok {
+        return nil
+    }
+    obtido := conferivel.Checksum()
+    metricas.Checksum = obtido.Valor
+    if !opcoesColeta.ConferirChecksum {
+        return nil
+    }
+    referencia, err := conferivel.ChecksumReferencia(ctx)
+    if err != nil {
+        if errors.Is(err, ctx.Err()) {
+            return nil
+        }
+        return err
+    }
+    if !obtido.Confere(referencia) {
+        return NovoErro(ErroKernel, errors.New("checksum diferente da referencia"), map[string]string{
+            "benchmark":  b.Nome(),
+            "checksum":   obtido.Valor,
+            "referencia": referencia.Valor,
+        })
+    }
+    metricas.ChecksumReferencia = referencia.Valor
+    return nil
+}
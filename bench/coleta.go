@@ -0,0 +1,31 @@
+package bench
+
+import "time"
+
+// OpcoesColeta ativa medicoes opcionais feitas em torno da regiao medida por
+// CapturarAmostraRecursos e ColetarMetricas.
+type OpcoesColeta struct {
+    // ContadoresPerf abre contadores de hardware via perf_event_open.
+    ContadoresPerf bool
+    // IntervaloAmostragem, quando positivo, liga o amostrador de fundo.
+    IntervaloAmostragem time.Duration
+    // ArquivoAmostragem recebe os pontos do amostrador em JSONL; vazio
+    // mantem a serie dentro do resultado.
+    ArquivoAmostragem string
+    // Caminhos dos perfis da regiao medida; vazio desliga cada um.
+    PerfilCpu      string
+    PerfilMemoria  string
+    PerfilBloqueio string
+    PerfilMutex    string
+    Rastro         string
+    // ConferirChecksum recalcula, depois de cada execucao completa, o
+    // checksum de referencia dos benchmarks Conferivel.
+    ConferirChecksum bool
+}
+
+var opcoesColeta OpcoesColeta
+
+// ConfigurarColeta define as medicoes opcionais das proximas execucoes.
+func ConfigurarColeta(opcoes OpcoesColeta) {
+    opcoesColeta = opcoes
+}
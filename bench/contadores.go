@@ -0,0 +1,38 @@
+package bench
+
+// ContadoresHardware traz os contadores de desempenho da regiao medida,
+// somados sobre as threads que o processo tinha no inicio da regiao (veja
+// ThreadsNaoCobertas). Contadores que o kernel ou a CPU nao oferecem ficam
+// nulos e o motivo aparece em erro; disponivel e falso quando nenhum contador
+// pode ser aberto.
+type ContadoresHardware struct {
+    Disponivel     bool   `json:"disponivel"`
+    Erro           string `json:"erro,omitempty"`
+    SomenteUsuario bool   `json:"somente_usuario,omitempty"`
+    // ThreadsCobertas e o numero de threads em que os contadores foram
+    // abertos; ThreadsNaoCobertas, as criadas durante a regiao e ainda vivas
+    // no fim, cuja contagem nao entra nos totais.
+    ThreadsCobertas    int      `json:"threads_cobertas"`
+    ThreadsNaoCobertas int      `json:"threads_nao_cobertas"`
+    Ciclos             *uint64  `json:"ciclos"`
+    Instrucoes         *uint64  `json:"instrucoes"`
+    InstrucoesPorCiclo *float64 `json:"instrucoes_por_ciclo,omitempty"`
+    ReferenciasLLC     *uint64  `json:"referencias_llc"`
+    FalhasLLC          *uint64  `json:"falhas_llc"`
+    FalhasDesvio       *uint64  `json:"falhas_previsao_desvio"`
+    TrocasContexto     *uint64  `json:"trocas_contexto"`
+    FaltasPagina       *uint64  `json:"faltas_pagina"`
+}
+
+// campo devolve o destino do contador indice em contadoresPerf.
+func (c *ContadoresHardware) campo(indice int) **uint64 {
+    return [...]**uint64{&c.Ciclos, &c.Instrucoes, &c.ReferenciasLLC, &c.FalhasLLC, &c.FalhasDesvio, &c.TrocasContexto, &c.FaltasPagina}[indice]
+}
+
+// completar calcula os valores derivados depois da leitura.
+func (c *ContadoresHardware) completar() {
+    if c.Ciclos != nil && c.Instrucoes != nil && *c.Ciclos > 0 {
+        ipc := float64(*c.Instrucoes) / float64(*c.Ciclos)
+        c.InstrucoesPorCiclo = &ipc
+    }
+}
@@ -0,0 +1,232 @@
+package bench
+
+import (
+    "encoding/binary"
+    "fmt"
+    "os"
+    "strconv"
+    "syscall"
+    "unsafe"
+)
+
+// atributosPerf espelha struct perf_event_attr (PERF_ATTR_SIZE_VER5).
+type atributosPerf struct {
+    tipo             uint32
+    tamanho          uint32
+    config           uint64
+    periodoAmostra   uint64
+    tipoAmostra      uint64
+    formatoLeitura   uint64
+    bits             uint64
+    wakeupEvents     uint32
+    bpType           uint32
+    config1          uint64
+    config2          uint64
+    branchSampleType uint64
+    sampleRegsUser   uint64
+    sampleStackUser  uint32
+    clockID          int32
+    sampleRegsIntr   uint64
+    auxWatermark     uint32
+    sampleMaxStack   uint16
+    _                uint16
+}
+
+const (
+    perfTipoHardware = 0
+    perfTipoSoftware = 1
+
+    perfBitDesabilitado    = 1 << 0
+    perfBitHerdar          = 1 << 1
+    perfBitExcluirKernel   = 1 << 5
+    perfBitExcluirHiperv   = 1 << 6
+    perfLerTempoHabilitado = 1 << 0
+    perfLerTempoExecutando = 1 << 1
+    perfFlagFdCloexec      = 1 << 3
+
+    perfIocHabilitar   = 0x2400 // PERF_EVENT_IOC_ENABLE
+    perfIocDesabilitar = 0x2401 // PERF_EVENT_IOC_DISABLE
+)
+
+// contadoresPerf segue a ordem de ContadoresHardware.campo.
+var contadoresPerf = [...]struct {
+    tipo   uint32
+    config uint64
+}{
+    {perfTipoHardware, 0}, // PERF_COUNT_HW_CPU_CYCLES
+    {perfTipoHardware, 1}, // PERF_COUNT_HW_INSTRUCTIONS
+    {perfTipoHardware, 2}, // PERF_COUNT_HW_CACHE_REFERENCES
+    {perfTipoHardware, 3}, // PERF_COUNT_HW_CACHE_MISSES
+    {perfTipoHardware, 5}, // PERF_COUNT_HW_BRANCH_MISSES
+    {perfTipoSoftware, 3}, // PERF_COUNT_SW_CONTEXT_SWITCHES
+    {perfTipoSoftware, 2}, // PERF_COUNT_SW_PAGE_FAULTS
+}
+
+// sessaoContadores guarda os descritores abertos por contador; cada contador
+// tem um descritor por thread existente na abertura, listada em tids.
+type sessaoContadores struct {
+    descritores    [len(contadoresPerf)][]int
+    tids           map[int]bool
+    erro           error
+    somenteUsuario bool
+}
+
+// listarThreads devolve os tids das threads do processo.
+func listarThreads() (map[int]bool, error) {
+    entradas, err := os.ReadDir("/proc/self/task")
+    if err != nil {
+        return nil, err
+    }
+    tids := make(map[int]bool, len(entradas))
+    for _, entrada := range entradas {
+        if tid, err := strconv.Atoi(entrada.Name()); err == nil {
+            tids[tid] = true
+        }
+    }
+    return tids, nil
+}
+
+func abrirPerf(atributos *atributosPerf, tid int) (int, error) {
+    fd, _, errno := syscall.Syscall6(syscall.SYS_PERF_EVENT_OPEN, uintptr(unsafe.Pointer(atributos)), uintptr(tid), ^uintptr(0), ^uintptr(0), perfFlagFdCloexec, 0)
+    if errno != 0 {
+        return -1, errno
+    }
+    return int(fd), nil
+}
+
+// abrirContadores abre, desligados, cada contador em todas as threads que o
+// processo tem na abertura; habilitar e desabilitar os ligam so em volta do
+// kernel. Com inherit, a contagem de uma thread criada depois so e
+// somada ao descritor da thread que a criou quando ela termina; como as
+// threads do runtime do Go nao terminam, o que elas executam na regiao medida
+// fica de fora, e encerrar informa quantas threads ficaram sem cobertura. Se
+// o kernel recusar a contagem em modo kernel (perf_event_paranoid >= 2),
+// tenta novamente contando apenas o espaco de usuario.
+func abrirContadores() *sessaoContadores {
+    sessao := &sessaoContadores{}
+    tids, err := listarThreads()
+    if err != nil {
+        sessao.erro = err
+        return sessao
+    }
+    sessao.tids = tids
+    for indice, contador := range contadoresPerf {
+        atributos := atributosPerf{
+            tipo:           contador.tipo,
+            config:         contador.config,
+            formatoLeitura: perfLerTempoHabilitado | perfLerTempoExecutando,
+            bits:           perfBitDesabilitado | perfBitHerdar,
+        }
+        atributos.tamanho = uint32(unsafe.Sizeof(atributos))
+        if sessao.somenteUsuario {
+            atributos.bits |= perfBitExcluirKernel | perfBitExcluirHiperv
+        }
+        var descritores []int
+        for tid := range tids {
+            fd, err := abrirPerf(&atributos, tid)
+            if (err == syscall.EACCES || err == syscall.EPERM) && !sessao.somenteUsuario {
+                sessao.somenteUsuario = true
+                atributos.bits |= perfBitExcluirKernel | perfBitExcluirHiperv
+                fd, err = abrirPerf(&atributos, tid)
+            }
+            if err == syscall.ESRCH {
+                continue // a thread terminou durante a abertura
+            }
+            if err != nil {
+                fecharDescritores(descritores)
+                descritores = nil
+                if sessao.erro == nil {
+                    if err == syscall.ENOENT || err == syscall.EOPNOTSUPP {
+                        err = fmt.Errorf("evento nao suportado pela CPU ou pelo hipervisor: %w", err)
+                    }
+                    sessao.erro = fmt.Errorf("perf_event_open(tipo %d, config %d): %w", contador.tipo, contador.config, err)
+                }
+                break
+            }
+            descritores = append(descritores, fd)
+        }
+        sessao.descritores[indice] = descritores
+    }
+    return sessao
+}
+
+// habilitar liga os contadores; e a ultima coisa feita ao abrir a regiao
+// medida, para que a propria coleta nao seja contada.
+func (sessao *sessaoContadores) habilitar() {
+    sessao.controlar(perfIocHabilitar)
+}
+
+// desabilitar congela os contadores assim que o kernel termina, antes de
+// qualquer leitura de metricas.
+func (sessao *sessaoContadores) desabilitar() {
+    sessao.controlar(perfIocDesabilitar)
+}
+
+func (sessao *sessaoContadores) controlar(requisicao uintptr) {
+    if sessao == nil {
+        return
+    }
+    for _, descritores := range sessao.descritores {
+        for _, fd := range descritores {
+            syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), requisicao, 0)
+        }
+    }
+}
+
+// encerrar le e fecha os contadores. Valores multiplexados pelo kernel sao
+// escalados pela fracao do tempo em que o contador esteve ativo. As threads
+// que existem agora e nao existiam na abertura sao contadas em
+// ThreadsNaoCobertas.
+func (sessao *sessaoContadores) encerrar() *ContadoresHardware {
+    if sessao == nil {
+        return nil
+    }
+    contadores := &ContadoresHardware{SomenteUsuario: sessao.somenteUsuario, ThreadsCobertas: len(sessao.tids)}
+    if tids, err := listarThreads(); err == nil {
+        for tid := range tids {
+            if !sessao.tids[tid] {
+                contadores.ThreadsNaoCobertas++
+            }
+        }
+    }
+    var leitura [24]byte
+    for indice, descritores := range sessao.descritores {
+        if len(descritores) == 0 {
+            continue
+        }
+        total := 0.0
+        lidos := 0
+        for _, fd := range descritores {
+            if n, err := syscall.Read(fd, leitura[:]); err != nil || n != len(leitura) {
+                continue
+            }
+            valor := binary.LittleEndian.Uint64(leitura[0:])
+            habilitado := binary.LittleEndian.Uint64(leitura[8:])
+            executando := binary.LittleEndian.Uint64(leitura[16:])
+            if executando > 0 && executando < habilitado {
+                total += float64(valor) * float64(habilitado) / float64(executando)
+            } else {
+                total += float64(valor)
+            }
+            lidos++
+        }
+        fecharDescritores(descritores)
+        if lidos == 0 {
+            continue
+        }
+        valor := uint64(total)
+        *contadores.campo(indice) = &valor
+        contadores.Disponivel = true
+    }
+    if sessao.erro != nil {
+        contadores.Erro = sessao.erro.Error()
+    }
+    contadores.completar()
+    return contadores
+}
+
+func fecharDescritores(descritores []int) {
+    for _, fd := range descritores {
+        syscall.Close(fd)
+    }
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package bench
+
+type sessaoContadores struct{}
+
+func abrirContadores() *sessaoContadores {
+    return &sessaoContadores{}
+}
+
+func (sessao *sessaoContadores) habilitar() {}
+
+func (sessao *sessaoContadores) desabilitar() {}
+
+func (sessao *sessaoContadores) encerrar() *ContadoresHardware {
+    if sessao == nil {
+        return nil
+    }
+    return &ContadoresHardware{Erro: "contadores de desempenho so sao suportados no Linux"}
+}
@@ -0,0 +1,23 @@
+package bench
+
+import (
+    "os"
+    "strconv"
+    "strings"
+)
+
+func ObterIntEnv(nome string, padrao int) int {
+    if texto := strings.TrimSpace(os.Getenv(nome)); texto != "" {
+        if valor, err := strconv.Atoi(texto); err == nil {
+            return valor
+        }
+    }
+    return padrao
+}
+
+func ObterStringEnv(nome, padrao string) string {
+    if texto := strings.TrimSpace(os.Getenv(nome)); texto != "" {
+        return texto
+    }
+    return padrao
+}
@@ -0,0 +1,73 @@
+package bench
+
+import (
+    "errors"
+    "sync"
+)
+
+// Codigos de ErroBenchmark.
+const (
+    ErroUso        = "uso"
+    ErroPreparacao = "preparacao"
+    ErroKernel     = "kernel"
+    ErroInterno    = "interno"
+)
+
+// ErroBenchmark e o erro estruturado dos benchmarks: codigo separa erros de
+// uso, falhas na preparacao dos dados e falhas do kernel medido; contexto
+// traz os valores que ajudam a reproduzir o problema.
+type ErroBenchmark struct {
+    Codigo   string            `json:"codigo"`
+    Mensagem string            `json:"mensagem"`
+    Contexto map[string]string `json:"contexto,omitempty"`
+    causa    error
+}
+
+func NovoErro(codigo string, causa error, contexto map[string]string) *ErroBenchmark {
+    return &ErroBenchmark{Codigo: codigo, Mensagem: causa.Error(), Contexto: contexto, causa: causa}
+}
+
+func (e *ErroBenchmark) Error() string {
+    return e.Mensagem
+}
+
+func (e *ErroBenchmark) Unwrap() error {
+    return e.causa
+}
+
+// ClassificarErro devolve o ErroBenchmark contido em err ou, para erros sem
+// classificacao, um ErroBenchmark com codigo interno.
+func ClassificarErro(err error) *ErroBenchmark {
+    var estruturado *ErroBenchmark
+    if errors.As(err, &estruturado) {
+        return estruturado
+    }
+    return NovoErro(ErroInterno, err, nil)
+}
+
+var contagemErros struct {
+    sync.Mutex
+    porTipo map[string]int64
+}
+
+// ContarErro registra uma falha recuperavel do tipo indicado na regiao medida
+// (um arquivo que nao pode ser lido, por exemplo). As contagens aparecem no
+// campo erros do resultado.
+func ContarErro(tipo string) {
+    contagemErros.Lock()
+    defer contagemErros.Unlock()
+    if contagemErros.porTipo == nil {
+        contagemErros.porTipo = map[string]int64{}
+    }
+    contagemErros.porTipo[tipo]++
+}
+
+// coletarContagemErros devolve e zera as contagens; nil quando nao houve
+// falhas.
+func coletarContagemErros() map[string]int64 {
+    contagemErros.Lock()
+    defer contagemErros.Unlock()
+    contagens := contagemErros.porTipo
+    contagemErros.porTipo = nil
+    return contagens
+}
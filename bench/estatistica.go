@@ -0,0 +1,262 @@
+package bench
+
+import (
+    "math"
+    "sort"
+)
+
+type ResumoEstatistico struct {
+    Media        float64 `json:"media"`
+    Mediana      float64 `json:"mediana"`
+    Minimo       float64 `json:"minimo"`
+    Maximo       float64 `json:"maximo"`
+    DesvioPadrao float64 `json:"desvio_padrao"`
+    IC95Inferior float64 `json:"ic95_inferior"`
+    IC95Superior float64 `json:"ic95_superior"`
+}
+
+// quantisT975 guarda o quantil 0,975 da distribuicao t de Student para 1 a 30
+// graus de liberdade.
+var quantisT975 = []float64{
+    12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+    2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+    2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func quantilT975(grausLiberdade int) float64 {
+    if grausLiberdade < 1 {
+        return math.NaN()
+    }
+    if grausLiberdade <= len(quantisT975) {
+        return quantisT975[grausLiberdade-1]
+    }
+    // Expansao de Cornish-Fisher em torno do quantil normal; erro < 0,001 acima de 30 gl.
+    z := 1.959964
+    gl := float64(grausLiberdade)
+    return z + (z*z*z+z)/(4*gl) + (5*math.Pow(z, 5)+16*z*z*z+3*z)/(96*gl*gl)
+}
+
+func Media(valores []float64) float64 {
+    if len(valores) == 0 {
+        return 0
+    }
+    soma := 0.0
+    for _, valor := range valores {
+        soma += valor
+    }
+    return soma / float64(len(valores))
+}
+
+// DesvioPadrao devolve o desvio padrao amostral (divisor n-1).
+func DesvioPadrao(valores []float64) float64 {
+    if len(valores) < 2 {
+        return 0
+    }
+    media := Media(valores)
+    soma := 0.0
+    for _, valor := range valores {
+        soma += (valor - media) * (valor - media)
+    }
+    return math.Sqrt(soma / float64(len(valores)-1))
+}
+
+func Mediana(valores []float64) float64 {
+    if len(valores) == 0 {
+        return 0
+    }
+    ordenados := append([]float64(nil), valores...)
+    sort.Float64s(ordenados)
+    meio := len(ordenados) / 2
+    if len(ordenados)%2 == 0 {
+        return (ordenados[meio-1] + ordenados[meio]) / 2
+    }
+    return ordenados[meio]
+}
+
+// Resumir calcula as estatisticas descritivas de valores. O intervalo de
+// confianca de 95% usa a distribuicao t e colapsa na media quando ha menos de
+// duas amostras.
+func Resumir(valores []float64) ResumoEstatistico {
+    if len(valores) == 0 {
+        return ResumoEstatistico{}
+    }
+    resumo := ResumoEstatistico{
+        Media:        Media(valores),
+        Mediana:      Mediana(valores),
+        Minimo:       valores[0],
+        Maximo:       valores[0],
+        DesvioPadrao: DesvioPadrao(valores),
+    }
+    for _, valor := range valores[1:] {
+        resumo.Minimo = math.Min(resumo.Minimo, valor)
+        resumo.Maximo = math.Max(resumo.Maximo, valor)
+    }
+    margem := 0.0
+    if len(valores) > 1 {
+        margem = quantilT975(len(valores)-1) * resumo.DesvioPadrao / math.Sqrt(float64(len(valores)))
+    }
+    resumo.IC95Inferior = resumo.Media - margem
+    resumo.IC95Superior = resumo.Media + margem
+    return resumo
+}
+
+// TesteMannWhitney devolve o valor p bilateral do teste U de Mann-Whitney
+// entre duas amostras independentes. Usa a distribuicao exata quando nao ha
+// empates e as amostras sao pequenas, e a aproximacao normal com correcao de
+// continuidade e de empates nos demais casos.
+func TesteMannWhitney(amostraA, amostraB []float64) float64 {
+    n1, n2 := len(amostraA), len(amostraB)
+    if n1 == 0 || n2 == 0 {
+        return math.NaN()
+    }
+    type valorRotulado struct {
+        valor      float64
+        daPrimeira bool
+    }
+    combinados := make([]valorRotulado, 0, n1+n2)
+    for _, valor := range amostraA {
+        combinados = append(combinados, valorRotulado{valor, true})
+    }
+    for _, valor := range amostraB {
+        combinados = append(combinados, valorRotulado{valor, false})
+    }
+    sort.Slice(combinados, func(i, j int) bool { return combinados[i].valor < combinados[j].valor })
+    somaPostosA := 0.0
+    correcaoEmpates := 0.0
+    for inicio := 0; inicio < len(combinados); {
+        fim := inicio
+        for fim+1 < len(combinados) && combinados[fim+1].valor == combinados[inicio].valor {
+            fim++
+        }
+        postoMedio := float64(inicio+fim)/2 + 1
+        for indice := inicio; indice <= fim; indice++ {
+            if combinados[indice].daPrimeira {
+                somaPostosA += postoMedio
+            }
+        }
+        empatados := float64(fim - inicio + 1)
+        correcaoEmpates += empatados*empatados*empatados - empatados
+        inicio = fim + 1
+    }
+    estatisticaU := somaPostosA - float64(n1*(n1+1))/2
+    if correcaoEmpates == 0 && n1+n2 <= 40 {
+        return valorPMannWhitneyExato(n1, n2, estatisticaU)
+    }
+    total := float64(n1 + n2)
+    media := float64(n1*n2) / 2
+    variancia := float64(n1*n2) / 12 * ((total + 1) - correcaoEmpates/(total*(total-1)))
+    if variancia <= 0 {
+        return 1
+    }
+    z := math.Max(0, math.Abs(estatisticaU-media)-0.5) / math.Sqrt(variancia)
+    return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// valorPMannWhitneyExato conta as configuracoes de postos com cada valor de U
+// pela recorrencia c(i, j, u) = c(i-1, j, u-j) + c(i, j-1, u).
+func valorPMannWhitneyExato(n1, n2 int, estatisticaU float64) float64 {
+    maximoU := n1 * n2
+    contagens := make([][]float64, n2+1)
+    for j := range contagens {
+        contagens[j] = make([]float64, maximoU+1)
+        contagens[j][0] = 1
+    }
+    for i := 1; i <= n1; i++ {
+        proximas := make([][]float64, n2+1)
+        proximas[0] = make([]float64, maximoU+1)
+        proximas[0][0] = 1
+        for j := 1; j <= n2; j++ {
+            proximas[j] = make([]float64, maximoU+1)
+            for u := 0; u <= i*j; u++ {
+                if u >= j {
+                    proximas[j][u] += contagens[j][u-j]
+                }
+                proximas[j][u] += proximas[j-1][u]
+            }
+        }
+        contagens = proximas
+    }
+    distribuicao := contagens[n2]
+    total, abaixo, acima := 0.0, 0.0, 0.0
+    for u, quantidade := range distribuicao {
+        total += quantidade
+        if float64(u) <= estatisticaU {
+            abaixo += quantidade
+        }
+        if float64(u) >= estatisticaU {
+            acima += quantidade
+        }
+    }
+    return math.Min(1, 2*math.Min(abaixo, acima)/total)
+}
+
+// TesteWelch devolve o valor p bilateral do teste t de Welch para medias de
+// duas amostras com variancias possivelmente diferentes.
+func TesteWelch(amostraA, amostraB []float64) float64 {
+    n1, n2 := float64(len(amostraA)), float64(len(amostraB))
+    if n1 < 2 || n2 < 2 {
+        return math.NaN()
+    }
+    mediaA, mediaB := Media(amostraA), Media(amostraB)
+    varianciaA := math.Pow(DesvioPadrao(amostraA), 2) / n1
+    varianciaB := math.Pow(DesvioPadrao(amostraB), 2) / n2
+    if varianciaA+varianciaB == 0 {
+        if mediaA == mediaB {
+            return 1
+        }
+        return 0
+    }
+    estatisticaT := (mediaA - mediaB) / math.Sqrt(varianciaA+varianciaB)
+    grausLiberdade := math.Pow(varianciaA+varianciaB, 2) /
+        (varianciaA*varianciaA/(n1-1) + varianciaB*varianciaB/(n2-1))
+    return betaIncompletaRegularizada(grausLiberdade/(grausLiberdade+estatisticaT*estatisticaT), grausLiberdade/2, 0.5)
+}
+
+// betaIncompletaRegularizada calcula I_x(a, b) pela fracao continua de Lentz.
+func betaIncompletaRegularizada(x, a, b float64) float64 {
+    if x <= 0 {
+        return 0
+    }
+    if x >= 1 {
+        return 1
+    }
+    if x > (a+1)/(a+b+2) {
+        return 1 - betaIncompletaRegularizada(1-x, b, a)
+    }
+    lgA, _ := math.Lgamma(a)
+    lgB, _ := math.Lgamma(b)
+    lgAB, _ := math.Lgamma(a + b)
+    fator := math.Exp(lgAB - lgA - lgB + a*math.Log(x) + b*math.Log(1-x))
+    const minimo = 1e-300
+    c, d := 1.0, 1-(a+b)*x/(a+1)
+    if math.Abs(d) < minimo {
+        d = minimo
+    }
+    d = 1 / d
+    fracao := d
+    for m := 1; m <= 300; m++ {
+        mf := float64(m)
+        for passo := 0; passo < 2; passo++ {
+            var numerador float64
+            if passo == 0 {
+                numerador = mf * (b - mf) * x / ((a + 2*mf - 1) * (a + 2*mf))
+            } else {
+                numerador = -(a + mf) * (a + b + mf) * x / ((a + 2*mf) * (a + 2*mf + 1))
+            }
+            d = 1 + numerador*d
+            if math.Abs(d) < minimo {
+                d = minimo
+            }
+            c = 1 + numerador/c
+            if math.Abs(c) < minimo {
+                c = minimo
+            }
+            d = 1 / d
+            fracao *= d * c
+            if passo == 1 && math.Abs(d*c-1) < 1e-14 {
+                return fator * fracao / a
+            }
+        }
+    }
+    return fator * fracao / a
+}
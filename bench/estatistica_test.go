@@ -0,0 +1,78 @@
+package bench
+
+import (
+    "math"
+    "testing"
+)
+
+// Amostras do conjunto sleep do R (grupos 1 e 2) e do exemplo de
+// wilcox.test; os valores p de referencia sao os do R (t.test,
+// wilcox.test com correct = TRUE) com mais casas, recalculados por enumeracao
+// exata e integracao numerica da densidade t.
+var (
+    sonoGrupo1 = []float64{0.7, -1.6, -0.2, -1.2, -0.1, 3.4, 3.7, 0.8, 0.0, 2.0}
+    sonoGrupo2 = []float64{1.9, 0.8, 1.1, 0.1, -0.1, 4.4, 5.5, 1.6, 4.6, 3.4}
+)
+
+func conferirValorP(t *testing.T, obtido, esperado float64) {
+    t.Helper()
+    if math.IsNaN(esperado) {
+        if !math.IsNaN(obtido) {
+            t.Errorf("valor p = %g, esperado NaN", obtido)
+        }
+        return
+    }
+    if math.Abs(obtido-esperado) > 1e-6*math.Max(1e-3, esperado) {
+        t.Errorf("valor p = %.10g, esperado %.10g", obtido, esperado)
+    }
+}
+
+func TestTesteMannWhitney(t *testing.T) {
+    casos := []struct {
+        nome     string
+        a, b     []float64
+        esperado float64
+    }{
+        // Distribuicao exata: sem empates e n1+n2 <= 40.
+        {nome: "exato/separadas", a: []float64{1, 2, 3}, b: []float64{4, 5, 6}, esperado: 0.1},
+        {nome: "exato/intercaladas", a: []float64{1, 3, 5}, b: []float64{2, 4, 6}, esperado: 0.7},
+        {nome: "exato/simetrico", a: []float64{4, 5, 6}, b: []float64{1, 2, 3}, esperado: 0.1},
+        {nome: "exato/n=5+5", a: []float64{1, 2, 3, 4, 5}, b: []float64{6, 7, 8, 9, 10}, esperado: 2.0 / 252},
+        // wilcox.test(x, y): W = 35, p = 0.2544.
+        {nome: "exato/wilcox.test", a: []float64{0.80, 0.83, 1.89, 1.04, 1.45, 1.38, 1.91, 1.64, 0.73, 1.46}, b: []float64{1.15, 0.88, 0.90, 0.74, 1.21}, esperado: 0.2544122544122544},
+        // Empates: aproximacao normal com correcao de continuidade.
+        // wilcox.test(extra ~ group, data = sleep): W = 25.5, p = 0.06933.
+        {nome: "normal/sleep", a: sonoGrupo1, b: sonoGrupo2, esperado: 0.06932757543362662},
+        {nome: "normal/empates", a: []float64{1, 2, 2, 3, 3, 3}, b: []float64{3, 4, 4, 5}, esperado: 0.026826219079541535},
+        {nome: "normal/iguais", a: []float64{2, 2, 2}, b: []float64{2, 2, 2}, esperado: 1},
+        {nome: "vazia", a: nil, b: []float64{1, 2}, esperado: math.NaN()},
+    }
+    for _, caso := range casos {
+        t.Run(caso.nome, func(t *testing.T) {
+            conferirValorP(t, TesteMannWhitney(caso.a, caso.b), caso.esperado)
+        })
+    }
+}
+
+func TestTesteWelch(t *testing.T) {
+    casos := []struct {
+        nome     string
+        a, b     []float64
+        esperado float64
+    }{
+        // t.test(extra ~ group, data = sleep): t = -1.8608, df = 17.776,
+        // p = 0.07939.
+        {nome: "sleep", a: sonoGrupo1, b: sonoGrupo2, esperado: 0.07939414018735509},
+        {nome: "sleep/simetrico", a: sonoGrupo2, b: sonoGrupo1, esperado: 0.07939414018735509},
+        // t = -5.9855, df = 7.4247.
+        {nome: "tamanhos diferentes", a: []float64{10.1, 9.8, 10.4, 10.0, 9.9}, b: []float64{11.2, 12.5, 10.9, 13.1, 11.8, 12.2, 11.5}, esperado: 0.00043919979966252834},
+        {nome: "sem variancia/iguais", a: []float64{3, 3}, b: []float64{3, 3, 3}, esperado: 1},
+        {nome: "sem variancia/diferentes", a: []float64{3, 3}, b: []float64{4, 4}, esperado: 0},
+        {nome: "uma amostra", a: []float64{1}, b: []float64{1, 2}, esperado: math.NaN()},
+    }
+    for _, caso := range casos {
+        t.Run(caso.nome, func(t *testing.T) {
+            conferirValorP(t, TesteWelch(caso.a, caso.b), caso.esperado)
+        })
+    }
+}
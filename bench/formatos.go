@@ -0,0 +1,164 @@
+package bench
+
+import (
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "strconv"
+    "text/tabwriter"
+)
+
+var FormatosSaida = []string{"json", "jsonl", "csv", "table"}
+
+// Emissor escreve resultados num formato de saida. Finalizar deve ser chamado
+// depois do ultimo resultado, pois alguns formatos so escrevem no fim.
+type Emissor interface {
+    Emitir(metricas MetricasBenchmark) error
+    Finalizar() error
+}
+
+// NovoEmissor cria o emissor do formato pedido:
+//   - json: um unico documento por execucao (objeto, ou array quando ha varios resultados);
+//   - jsonl: um objeto por linha, escrito assim que cada resultado termina;
+//   - csv: cabecalho derivado das tags JSON dos campos escalares e uma linha por resultado;
+//   - table: tabela alinhada para leitura humana.
+//
+// comCabecalho controla se csv e table escrevem a linha de cabecalho, o que
+// permite acrescentar linhas a um arquivo ja existente.
+func NovoEmissor(formato string, saida io.Writer, comCabecalho bool) (Emissor, error) {
+    switch formato {
+    case "json":
+        return &emissorJSON{saida: saida}, nil
+    case "jsonl":
+        return &emissorJSONL{saida: saida}, nil
+    case "csv":
+        return &emissorCSV{escritor: csv.NewWriter(saida), comCabecalho: comCabecalho}, nil
+    case "table":
+        return &emissorTabela{saida: saida, comCabecalho: comCabecalho}, nil
+    default:
+        return nil, fmt.Errorf("formato de saida desconhecido: %q", formato)
+    }
+}
+
+func EmitirMetricas(saida io.Writer, metricas MetricasBenchmark) error {
+    emissor := &emissorJSONL{saida: saida}
+    return emissor.Emitir(metricas)
+}
+
+type emissorJSONL struct {
+    saida io.Writer
+}
+
+func (emissor *emissorJSONL) Emitir(metricas MetricasBenchmark) error {
+    dadosMetricas, err := json.Marshal(metricas)
+    if err != nil {
+        return err
+    }
+    _, err = fmt.Fprintln(emissor.saida, string(dadosMetricas))
+    return err
+}
+
+func (emissor *emissorJSONL) Finalizar() error {
+    return nil
+}
+
+type emissorJSON struct {
+    saida      io.Writer
+    resultados []MetricasBenchmark
+}
+
+func (emissor *emissorJSON) Emitir(metricas MetricasBenchmark) error {
+    emissor.resultados = append(emissor.resultados, metricas)
+    return nil
+}
+
+func (emissor *emissorJSON) Finalizar() error {
+    var documento any = emissor.resultados
+    switch len(emissor.resultados) {
+    case 0:
+        return nil
+    case 1:
+        documento = emissor.resultados[0]
+    }
+    dados, err := json.Marshal(documento)
+    if err != nil {
+        return err
+    }
+    _, err = fmt.Fprintln(emissor.saida, string(dados))
+    return err
+}
+
+type emissorCSV struct {
+    escritor     *csv.Writer
+    comCabecalho bool
+}
+
+func formatarValorTabular(valor any) string {
+    switch convertido := valor.(type) {
+    case float64:
+        return strconv.FormatFloat(convertido, 'f', -1, 64)
+    default:
+        return fmt.Sprint(valor)
+    }
+}
+
+func (emissor *emissorCSV) Emitir(metricas MetricasBenchmark) error {
+    if emissor.comCabecalho {
+        var cabecalho []string
+        for _, campo := range camposTabulares {
+            cabecalho = append(cabecalho, campo.Nome)
+        }
+        if err := emissor.escritor.Write(cabecalho); err != nil {
+            return err
+        }
+        emissor.comCabecalho = false
+    }
+    linha := make([]string, 0, len(camposTabulares))
+    for _, campo := range camposTabulares {
+        linha = append(linha, formatarValorTabular(campo.Valor(metricas)))
+    }
+    if err := emissor.escritor.Write(linha); err != nil {
+        return err
+    }
+    emissor.escritor.Flush()
+    return emissor.escritor.Error()
+}
+
+func (emissor *emissorCSV) Finalizar() error {
+    emissor.escritor.Flush()
+    return emissor.escritor.Error()
+}
+
+type emissorTabela struct {
+    saida        io.Writer
+    comCabecalho bool
+    resultados   []MetricasBenchmark
+}
+
+func (emissor *emissorTabela) Emitir(metricas MetricasBenchmark) error {
+    emissor.resultados = append(emissor.resultados, metricas)
+    return nil
+}
+
+func (emissor *emissorTabela) Finalizar() error {
+    if len(emissor.resultados) == 0 {
+        return nil
+    }
+    tabela := tabwriter.NewWriter(emissor.saida, 0, 4, 2, ' ', tabwriter.AlignRight)
+    if emissor.comCabecalho {
+        fmt.Fprintln(tabela, "problema\ttamanho\tthreads\treps\ttempo_ms\tdesvio_ms\tcpu_ms\tcpu_%\trss_mb\titens\toperacoes\titeracoes\tspeedup\teficiencia\t")
+    }
+    for _, metricas := range emissor.resultados {
+        desvio := 0.0
+        if metricas.Estatisticas != nil {
+            desvio = metricas.Estatisticas.ParedeMs.DesvioPadrao
+        }
+        fmt.Fprintf(tabela, "%s\t%d\t%d\t%d\t%.3f\t%.3f\t%.3f\t%.1f\t%.2f\t%d\t%d\t%d\t%.3f\t%.3f\t\n",
+            metricas.Problema, metricas.Tamanho, metricas.Threads, metricas.Repeticoes,
+            metricas.ParedeMs, desvio, metricas.CpuMs, metricas.CpuPct, metricas.RSSMb,
+            metricas.ItensProcessados, metricas.OperacoesRealizadas, metricas.IteracoesRealizadas,
+            metricas.Speedup, metricas.Eficiencia)
+    }
+    return tabela.Flush()
+}
@@ -0,0 +1,21 @@
+package bench
+
+import "context"
+
+// Valores do campo status do resultado.
+const (
+    StatusConcluido = "ok"
+    StatusTimeout   = "timeout"
+)
+
+// Interrompido informa, sem bloquear, se ctx foi cancelado ou expirou. Lacos
+// curtos devem consulta-lo a cada algumas centenas de iteracoes para nao
+// pesar na medicao.
+func Interrompido(ctx context.Context) bool {
+    select {
+    case <-ctx.Done():
+        return true
+    default:
+        return false
+    }
+}
@@ -0,0 +1,104 @@
+// Package bench concentra a coleta de metricas compartilhada pelos
+// benchmarks em Go: amostragem de recursos, o tipo de resultado e a
+// emissao em JSON.
+package bench
+
+import (
+    "runtime"
+    "time"
+)
+
+type MetricasBenchmark struct {
+    Problema            string  `json:"nome_problema"`
+    Tamanho             int     `json:"tamanho_instancia"`
+    Threads             int     `json:"quantidade_threads"`
+    ParedeMs            float64 `json:"tempo_decorrido_ms"`
+    CpuMs               float64 `json:"tempo_cpu_ms"`
+    CpuPct              float64 `json:"percentual_uso_cpu"`
+    CpuPctPorNucleo     float64 `json:"percentual_uso_cpu_por_nucleo"`
+    RSSMb               float64 `json:"memoria_rss_mb"`
+    MemoriaBaselineMb   float64 `json:"memoria_baseline_mb"`
+    MemoriaSetupMb      float64 `json:"memoria_setup_mb"`
+    MemoriaPicoKernelMb float64 `json:"memoria_pico_kernel_mb"`
+    ItensProcessados    int64   `json:"itens_processados"`
+    OperacoesRealizadas int64   `json:"operacoes_realizadas"`
+    IteracoesRealizadas int64   `json:"iteracoes_realizadas"`
+    Status              string  `json:"status"`
+    IdExecucao          string  `json:"id_execucao,omitempty"`
+    Checksum            string  `json:"checksum,omitempty"`
+    ChecksumReferencia  string  `json:"checksum_referencia,omitempty"`
+
+    Erros map[string]int64 `json:"erros,omitempty"`
+
+    Repeticoes   int                     `json:"repeticoes"`
+    Aquecimento  int                     `json:"aquecimento"`
+    Estatisticas *EstatisticasRepeticoes `json:"estatisticas,omitempty"`
+    Amostras     []AmostraRepeticao      `json:"amostras,omitempty"`
+
+    CpuThreads *CpuPorThread       `json:"cpu_por_thread,omitempty"`
+    Contadores *ContadoresHardware `json:"contadores,omitempty"`
+    Amostragem *SerieAmostragem    `json:"amostragem,omitempty"`
+    Runtime    *MetricasRuntime    `json:"runtime,omitempty"`
+    UsoSO      *UsoRecursosSO      `json:"rusage,omitempty"`
+    Perfis     *PerfisExecucao     `json:"perfis,omitempty"`
+
+    Speedup    float64 `json:"speedup,omitempty"`
+    Eficiencia float64 `json:"eficiencia,omitempty"`
+
+    Parametros map[string]string   `json:"parametros,omitempty"`
+    Ambiente   *Ambiente           `json:"ambiente,omitempty"`
+    Afinidade  *RelatorioAfinidade `json:"afinidade,omitempty"`
+}
+
+// ColetarMetricas fecha a regiao medida iniciada por amostraInicial e monta o
+// resultado do benchmark.
+func ColetarMetricas(nomeProblema string, tamanhoBenchmark, totalThreads int, amostraInicial AmostraRecursos, itensProcessados, operacoesRealizadas, iteracoesRealizadas int64) MetricasBenchmark {
+    amostraInicial.contadores.desabilitar()
+    // Tempo de parede, rusage e CPU por thread fecham juntos, antes de parar
+    // os coletores: gravar perfis e encerrar o amostrador nao entra na regiao.
+    amostraFinal := AmostraRecursos{momentoParede: time.Now(), uso: lerUsoRecursos()}
+    amostraFinal.tempoThreads = lerTempoThreads()
+    amostraFinal.consumoCpuMs = tempoCpuUso(amostraFinal.uso)
+    contadores := amostraInicial.contadores.encerrar()
+    amostraFinal.runtime = lerMetricasRuntime()
+    perfis := amostraInicial.perfis.encerrar()
+    serie := amostraInicial.amostrador.encerrar(nomeProblema, tamanhoBenchmark, totalThreads, amostraInicial.execucao)
+    tempoParede := amostraFinal.momentoParede.Sub(amostraInicial.momentoParede).Seconds() * 1000.0
+    tempoCpu := amostraFinal.consumoCpuMs - amostraInicial.consumoCpuMs
+    percentualCpu := 0.0
+    if tempoParede > 0 {
+        percentualCpu = (tempoCpu / tempoParede) * 100.0
+    }
+    percentualCpuPorNucleo := 0.0
+    if nucleos := runtime.NumCPU(); nucleos > 0 {
+        percentualCpuPorNucleo = percentualCpu / float64(nucleos)
+    }
+    picoKernel := MemoriaRssEmMb()
+    ambiente := ColetarAmbiente()
+    return MetricasBenchmark{
+        Problema:            nomeProblema,
+        Tamanho:             tamanhoBenchmark,
+        Threads:             totalThreads,
+        ParedeMs:            tempoParede,
+        CpuMs:               tempoCpu,
+        CpuPct:              percentualCpu,
+        CpuPctPorNucleo:     percentualCpuPorNucleo,
+        RSSMb:               max(amostraInicial.picoSetup, picoKernel),
+        MemoriaSetupMb:      amostraInicial.memoriaSetup,
+        MemoriaPicoKernelMb: picoKernel,
+        ItensProcessados:    itensProcessados,
+        OperacoesRealizadas: operacoesRealizadas,
+        IteracoesRealizadas: iteracoesRealizadas,
+        Status:              StatusConcluido,
+        Erros:               coletarContagemErros(),
+        Repeticoes:          1,
+        Ambiente:            &ambiente,
+        Afinidade:           relatorioAfinidade(),
+        CpuThreads:          calcularCpuPorThread(amostraInicial.tempoThreads, amostraFinal.tempoThreads),
+        Contadores:          contadores,
+        Amostragem:          serie,
+        Runtime:             calcularMetricasRuntime(amostraInicial.runtime, amostraFinal.runtime),
+        UsoSO:               calcularUsoRecursos(amostraInicial.uso, amostraFinal.uso),
+        Perfis:              perfis,
+    }
+}
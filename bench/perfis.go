@@ -0,0 +1,138 @@
+package bench
+
+import (
+    "os"
+    "path/filepath"
+    "runtime"
+    "runtime/pprof"
+    "runtime/trace"
+    "strconv"
+    "strings"
+)
+
+// PerfisExecucao lista os arquivos gravados para a regiao medida. Os perfis
+// de memoria, bloqueio e mutex do runtime sao cumulativos; os arquivos *_base
+// sao retratos tirados no inicio da regiao, para uso com go tool pprof -base.
+type PerfisExecucao struct {
+    Cpu          string `json:"cpu,omitempty"`
+    Memoria      string `json:"memoria,omitempty"`
+    MemoriaBase  string `json:"memoria_base,omitempty"`
+    Bloqueio     string `json:"bloqueio,omitempty"`
+    BloqueioBase string `json:"bloqueio_base,omitempty"`
+    Mutex        string `json:"mutex,omitempty"`
+    MutexBase    string `json:"mutex_base,omitempty"`
+    Rastro       string `json:"rastro,omitempty"`
+    Erro         string `json:"erro,omitempty"`
+}
+
+type sessaoPerfis struct {
+    execucao      int
+    perfis        PerfisExecucao
+    arquivoCpu    *os.File
+    arquivoRastro *os.File
+    erros         []string
+}
+
+// caminhoPerfil devolve o caminho pedido na primeira regiao medida e, nas
+// seguintes, o mesmo caminho com o numero da execucao antes da extensao
+// (cpu.prof, cpu.2.prof, ...).
+func caminhoPerfil(caminho string, execucao int) string {
+    if execucao <= 1 {
+        return caminho
+    }
+    extensao := filepath.Ext(caminho)
+    return strings.TrimSuffix(caminho, extensao) + "." + strconv.Itoa(execucao) + extensao
+}
+
+func (sessao *sessaoPerfis) registrarErro(err error) {
+    if err != nil {
+        sessao.erros = append(sessao.erros, err.Error())
+    }
+}
+
+// gravarPerfil escreve o perfil nome do runtime em caminho.
+func (sessao *sessaoPerfis) gravarPerfil(nome, caminho string) string {
+    arquivo, err := os.Create(caminho)
+    if err != nil {
+        sessao.registrarErro(err)
+        return ""
+    }
+    sessao.registrarErro(pprof.Lookup(nome).WriteTo(arquivo, 0))
+    sessao.registrarErro(arquivo.Close())
+    return caminho
+}
+
+// iniciarPerfis liga os perfis pedidos em opcoesColeta; devolve nil quando
+// nenhum foi pedido.
+func iniciarPerfis(execucao int) *sessaoPerfis {
+    opcoes := opcoesColeta
+    if opcoes.PerfilCpu == "" && opcoes.PerfilMemoria == "" && opcoes.PerfilBloqueio == "" && opcoes.PerfilMutex == "" && opcoes.Rastro == "" {
+        return nil
+    }
+    sessao := &sessaoPerfis{execucao: execucao}
+    if opcoes.PerfilMemoria != "" {
+        sessao.perfis.MemoriaBase = sessao.gravarPerfil("heap", caminhoPerfil(opcoes.PerfilMemoria, execucao)+".base")
+    }
+    if opcoes.PerfilBloqueio != "" {
+        sessao.perfis.BloqueioBase = sessao.gravarPerfil("block", caminhoPerfil(opcoes.PerfilBloqueio, execucao)+".base")
+        runtime.SetBlockProfileRate(1)
+    }
+    if opcoes.PerfilMutex != "" {
+        sessao.perfis.MutexBase = sessao.gravarPerfil("mutex", caminhoPerfil(opcoes.PerfilMutex, execucao)+".base")
+        runtime.SetMutexProfileFraction(1)
+    }
+    if opcoes.Rastro != "" {
+        caminho := caminhoPerfil(opcoes.Rastro, execucao)
+        if arquivo, err := os.Create(caminho); err != nil {
+            sessao.registrarErro(err)
+        } else if err := trace.Start(arquivo); err != nil {
+            sessao.registrarErro(err)
+            arquivo.Close()
+        } else {
+            sessao.arquivoRastro = arquivo
+            sessao.perfis.Rastro = caminho
+        }
+    }
+    if opcoes.PerfilCpu != "" {
+        caminho := caminhoPerfil(opcoes.PerfilCpu, execucao)
+        if arquivo, err := os.Create(caminho); err != nil {
+            sessao.registrarErro(err)
+        } else if err := pprof.StartCPUProfile(arquivo); err != nil {
+            sessao.registrarErro(err)
+            arquivo.Close()
+        } else {
+            sessao.arquivoCpu = arquivo
+            sessao.perfis.Cpu = caminho
+        }
+    }
+    return sessao
+}
+
+// encerrar para os perfis continuos e grava os cumulativos.
+func (sessao *sessaoPerfis) encerrar() *PerfisExecucao {
+    if sessao == nil {
+        return nil
+    }
+    if sessao.arquivoCpu != nil {
+        pprof.StopCPUProfile()
+        sessao.registrarErro(sessao.arquivoCpu.Close())
+    }
+    if sessao.arquivoRastro != nil {
+        trace.Stop()
+        sessao.registrarErro(sessao.arquivoRastro.Close())
+    }
+    opcoes := opcoesColeta
+    if opcoes.PerfilBloqueio != "" {
+        runtime.SetBlockProfileRate(0)
+        sessao.perfis.Bloqueio = sessao.gravarPerfil("block", caminhoPerfil(opcoes.PerfilBloqueio, sessao.execucao))
+    }
+    if opcoes.PerfilMutex != "" {
+        runtime.SetMutexProfileFraction(0)
+        sessao.perfis.Mutex = sessao.gravarPerfil("mutex", caminhoPerfil(opcoes.PerfilMutex, sessao.execucao))
+    }
+    if opcoes.PerfilMemoria != "" {
+        sessao.perfis.Memoria = sessao.gravarPerfil("heap", caminhoPerfil(opcoes.PerfilMemoria, sessao.execucao))
+    }
+    sessao.perfis.Erro = strings.Join(sessao.erros, "; ")
+    return &sessao.perfis
+}
@@ -0,0 +1,144 @@
+package bench
+
+import (
+    "os"
+    "runtime/metrics"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+// UsoRecursosSO traz as diferencas de getrusage(RUSAGE_SELF) na regiao
+// medida.
+type UsoRecursosSO struct {
+    TrocasVoluntarias   int64 `json:"trocas_contexto_voluntarias"`
+    TrocasInvoluntarias int64 `json:"trocas_contexto_involuntarias"`
+    FaltasMenores       int64 `json:"faltas_pagina_menores"`
+    FaltasMaiores       int64 `json:"faltas_pagina_maiores"`
+    BlocosLidos         int64 `json:"blocos_lidos"`
+    BlocosEscritos      int64 `json:"blocos_escritos"`
+}
+
+// totalExecucoes numera as regioes medidas do processo, incluindo
+// aquecimentos; o numero identifica a serie do amostrador e os perfis.
+var totalExecucoes int64
+
+type AmostraRecursos struct {
+    execucao      int
+    momentoParede time.Time
+    consumoCpuMs  float64
+    uso           syscall.Rusage
+    tempoThreads  map[int]ticksThread
+    contadores    *sessaoContadores
+    perfis        *sessaoPerfis
+    amostrador    *amostrador
+    runtime       []metrics.Sample
+    memoriaSetup  float64
+    picoSetup     float64
+}
+
+// CapturarAmostraRecursos abre a regiao medida. Os contadores de desempenho
+// sao abertos desligados antes do relogio, para que a varredura de /proc fique
+// fora da medicao, e so ligados no fim, logo antes do kernel; o tempo de cada
+// thread e lido junto com o relogio. O pico de memoria do processo e
+// guardado e reiniciado, de modo que o VmHWM lido ao final reflita so o
+// kernel.
+func CapturarAmostraRecursos() AmostraRecursos {
+    amostra := AmostraRecursos{execucao: int(atomic.AddInt64(&totalExecucoes, 1))}
+    amostra.runtime = lerMetricasRuntime()
+    amostra.memoriaSetup = MemoriaAtualEmMb()
+    amostra.picoSetup = MemoriaRssEmMb()
+    reiniciarPicoMemoria()
+    coletarContagemErros()
+    amostra.perfis = iniciarPerfis(amostra.execucao)
+    if opcoesColeta.ContadoresPerf {
+        amostra.contadores = abrirContadores()
+    }
+    amostra.momentoParede = time.Now()
+    amostra.uso = lerUsoRecursos()
+    amostra.tempoThreads = lerTempoThreads()
+    amostra.consumoCpuMs = tempoCpuUso(amostra.uso)
+    if opcoesColeta.IntervaloAmostragem > 0 {
+        amostra.amostrador = iniciarAmostrador(opcoesColeta.IntervaloAmostragem, amostra.momentoParede)
+    }
+    amostra.contadores.habilitar()
+    return amostra
+}
+
+func TempoCpuEmMs() float64 {
+    return tempoCpuUso(lerUsoRecursos())
+}
+
+// lerUsoRecursos devolve getrusage(RUSAGE_SELF), zerado em caso de erro.
+func lerUsoRecursos() syscall.Rusage {
+    var ru syscall.Rusage
+    if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+        return syscall.Rusage{}
+    }
+    return ru
+}
+
+func tempoCpuUso(ru syscall.Rusage) float64 {
+    usuario := float64(ru.Utime.Sec)*1000.0 + float64(ru.Utime.Usec)/1000.0
+    sistema := float64(ru.Stime.Sec)*1000.0 + float64(ru.Stime.Usec)/1000.0
+    return usuario + sistema
+}
+
+// MemoriaRssEmMb devolve o pico de memoria residente (VmHWM) do processo e,
+// na falta dele, o RSS atual lido de /proc/self/statm.
+func MemoriaRssEmMb() float64 {
+    status, err := os.ReadFile("/proc/self/status")
+    if err == nil {
+        for _, linha := range strings.Split(string(status), "\n") {
+            if strings.HasPrefix(linha, "VmHWM:") {
+                campos := strings.Fields(linha)
+                if len(campos) >= 2 {
+                    if valor, err := strconv.ParseFloat(campos[1], 64); err == nil {
+                        return valor / 1024.0
+                    }
+                }
+                break
+            }
+        }
+    }
+    return MemoriaAtualEmMb()
+}
+
+func calcularUsoRecursos(inicio, fim syscall.Rusage) *UsoRecursosSO {
+    return &UsoRecursosSO{
+        TrocasVoluntarias:   int64(fim.Nvcsw - inicio.Nvcsw),
+        TrocasInvoluntarias: int64(fim.Nivcsw - inicio.Nivcsw),
+        FaltasMenores:       int64(fim.Minflt - inicio.Minflt),
+        FaltasMaiores:       int64(fim.Majflt - inicio.Majflt),
+        BlocosLidos:         int64(fim.Inblock - inicio.Inblock),
+        BlocosEscritos:      int64(fim.Oublock - inicio.Oublock),
+    }
+}
+
+// reiniciarPicoMemoria faz o kernel igualar o VmHWM ao RSS atual (escrita de
+// "5" em /proc/self/clear_refs, Linux 4.0+). Sem suporte, o pico continua
+// contando desde o inicio do processo.
+func reiniciarPicoMemoria() {
+    _ = os.WriteFile("/proc/self/clear_refs", []byte("5"), 0)
+}
+
+// MemoriaAtualEmMb devolve a memoria residente atual lida de
+// /proc/self/statm.
+func MemoriaAtualEmMb() float64 {
+    dados, err := os.ReadFile("/proc/self/statm")
+    if err != nil {
+        return 0.0
+    }
+    partes := strings.Fields(string(dados))
+    if len(partes) < 2 {
+        return 0.0
+    }
+    rssPaginas, err := strconv.ParseUint(partes[1], 10, 64)
+    if err != nil {
+        return 0.0
+    }
+    tamanhoPagina := os.Getpagesize()
+    return float64(rssPaginas*uint64(tamanhoPagina)) / (1024.0 * 1024.0)
+}
@@ -0,0 +1,114 @@
+package bench
+
+import (
+    "flag"
+    "fmt"
+    "sort"
+    "strconv"
+    "sync"
+)
+
+// Parametro descreve uma flag propria de um benchmark e o campo da
+// configuracao que ela altera. O valor do campo no momento do registro e o
+// padrao, substituido pela variavel Ambiente quando ela esta definida.
+type Parametro struct {
+    Nome      string
+    Ambiente  string
+    Descricao string
+    // Tipo e "int" ou "string".
+    Tipo         string
+    destinoInt   *int
+    destinoTexto *string
+}
+
+// ParametroInt liga a flag nome ao campo inteiro destino.
+func ParametroInt(destino *int, nome, ambiente, descricao string) Parametro {
+    return Parametro{Nome: nome, Ambiente: ambiente, Descricao: descricao, Tipo: "int", destinoInt: destino}
+}
+
+// ParametroTexto liga a flag nome ao campo de texto destino.
+func ParametroTexto(destino *string, nome, ambiente, descricao string) Parametro {
+    return Parametro{Nome: nome, Ambiente: ambiente, Descricao: descricao, Tipo: "string", destinoTexto: destino}
+}
+
+// Registrar define a flag em flags.
+func (p Parametro) Registrar(flags *flag.FlagSet) {
+    switch {
+    case p.destinoInt != nil:
+        padrao := *p.destinoInt
+        if p.Ambiente != "" {
+            padrao = ObterIntEnv(p.Ambiente, padrao)
+        }
+        flags.IntVar(p.destinoInt, p.Nome, padrao, p.Descricao)
+    case p.destinoTexto != nil:
+        padrao := *p.destinoTexto
+        if p.Ambiente != "" {
+            padrao = ObterStringEnv(p.Ambiente, padrao)
+        }
+        flags.StringVar(p.destinoTexto, p.Nome, padrao, p.Descricao)
+    }
+}
+
+// Valor devolve o valor atual do campo ligado ao parametro.
+func (p Parametro) Valor() string {
+    switch {
+    case p.destinoInt != nil:
+        return strconv.Itoa(*p.destinoInt)
+    case p.destinoTexto != nil:
+        return *p.destinoTexto
+    }
+    return ""
+}
+
+var registro struct {
+    sync.Mutex
+    construtores map[string]func() Benchmark
+}
+
+// Registrar torna o benchmark criado por novo visivel ao benchctl (run, list,
+// campaign, gate e relatorios). E chamada no init do pacote do problema;
+// registrar o mesmo nome duas vezes e um erro de programacao e causa panic.
+func Registrar(novo func() Benchmark) {
+    nome := novo().Nome()
+    registro.Lock()
+    defer registro.Unlock()
+    if registro.construtores == nil {
+        registro.construtores = map[string]func() Benchmark{}
+    }
+    if _, existe := registro.construtores[nome]; existe {
+        panic(fmt.Sprintf("bench: benchmark %q registrado duas vezes", nome))
+    }
+    registro.construtores[nome] = novo
+}
+
+// NovoBenchmark cria uma instancia, com a configuracao padrao, do benchmark
+// registrado com esse nome.
+func NovoBenchmark(nome string) (Benchmark, bool) {
+    registro.Lock()
+    novo, ok := registro.construtores[nome]
+    registro.Unlock()
+    if !ok {
+        return nil, false
+    }
+    return novo(), true
+}
+
+// NomesBenchmarks lista os benchmarks registrados em ordem alfabetica.
+func NomesBenchmarks() []string {
+    registro.Lock()
+    defer registro.Unlock()
+    nomes := make([]string, 0, len(registro.construtores))
+    for nome := range registro.construtores {
+        nomes = append(nomes, nome)
+    }
+    sort.Strings(nomes)
+    return nomes
+}
+
+// DescricaoBenchmark devolve a descricao do benchmark registrado ou vazio.
+func DescricaoBenchmark(nome string) string {
+    if b, ok := NovoBenchmark(nome); ok {
+        return b.Descricao()
+    }
+    return ""
+}
@@ -0,0 +1,105 @@
+package bench
+
+import (
+    "errors"
+    "runtime"
+    "strconv"
+)
+
+type EstatisticasRepeticoes struct {
+    ParedeMs ResumoEstatistico `json:"tempo_decorrido_ms"`
+    CpuMs    ResumoEstatistico `json:"tempo_cpu_ms"`
+}
+
+type AmostraRepeticao struct {
+    Repeticao int     `json:"repeticao"`
+    ParedeMs  float64 `json:"tempo_decorrido_ms"`
+    CpuMs     float64 `json:"tempo_cpu_ms"`
+}
+
+// ExecutarRepeticoes roda executar aquecimento vezes descartando o resultado e
+// depois repeticoes vezes, agregando as medicoes. No resultado agregado
+// tempo_decorrido_ms e tempo_cpu_ms sao as medias, memoria_rss_mb e
+// memoria_pico_kernel_mb sao os maiores picos observados e os contadores vem
+// da ultima repeticao, exceto erros, que soma as falhas de todas as repeticoes
+// medidas. Uma execucao com status timeout encerra a serie: ela e
+// devolvida com os contadores parciais, repeticoes indicando quantas rodadas
+// medidas terminaram antes e amostras com os tempos dessas rodadas.
+func ExecutarRepeticoes(repeticoes, aquecimento int, executar func() (MetricasBenchmark, error)) (MetricasBenchmark, error) {
+    if repeticoes < 1 {
+        return MetricasBenchmark{}, NovoErro(ErroUso, errors.New("o numero de repeticoes deve ser ao menos 1"), map[string]string{"repeticoes": strconv.Itoa(repeticoes)})
+    }
+    if aquecimento < 0 {
+        return MetricasBenchmark{}, NovoErro(ErroUso, errors.New("o numero de execucoes de aquecimento nao pode ser negativo"), map[string]string{"aquecimento": strconv.Itoa(aquecimento)})
+    }
+    for indice := 0; indice < aquecimento; indice++ {
+        metricas, err := executar()
+        if err != nil {
+            return MetricasBenchmark{}, err
+        }
+        if metricas.Status == StatusTimeout {
+            metricas.Repeticoes = 0
+            metricas.Aquecimento = indice
+            return metricas, nil
+        }
+    }
+    var agregado MetricasBenchmark
+    temposParede := make([]float64, 0, repeticoes)
+    temposCpu := make([]float64, 0, repeticoes)
+    amostras := make([]AmostraRepeticao, 0, repeticoes)
+    picoRss, picoKernel := 0.0, 0.0
+    var erros map[string]int64
+    for indice := 0; indice < repeticoes; indice++ {
+        metricas, err := executar()
+        if err != nil {
+            return MetricasBenchmark{}, err
+        }
+        erros = somarErros(erros, metricas.Erros)
+        if metricas.Status == StatusTimeout {
+            metricas.Repeticoes = indice
+            metricas.Aquecimento = aquecimento
+            metricas.Erros = erros
+            if len(amostras) > 0 {
+                metricas.Amostras = amostras
+            }
+            return metricas, nil
+        }
+        agregado = metricas
+        temposParede = append(temposParede, metricas.ParedeMs)
+        temposCpu = append(temposCpu, metricas.CpuMs)
+        amostras = append(amostras, AmostraRepeticao{Repeticao: indice + 1, ParedeMs: metricas.ParedeMs, CpuMs: metricas.CpuMs})
+        picoRss = max(picoRss, metricas.RSSMb)
+        picoKernel = max(picoKernel, metricas.MemoriaPicoKernelMb)
+    }
+    estatisticas := EstatisticasRepeticoes{ParedeMs: Resumir(temposParede), CpuMs: Resumir(temposCpu)}
+    agregado.ParedeMs = estatisticas.ParedeMs.Media
+    agregado.CpuMs = estatisticas.CpuMs.Media
+    agregado.CpuPct = 0
+    if agregado.ParedeMs > 0 {
+        agregado.CpuPct = (agregado.CpuMs / agregado.ParedeMs) * 100.0
+    }
+    agregado.CpuPctPorNucleo = 0
+    if nucleos := runtime.NumCPU(); nucleos > 0 {
+        agregado.CpuPctPorNucleo = agregado.CpuPct / float64(nucleos)
+    }
+    agregado.Erros = erros
+    agregado.RSSMb = picoRss
+    agregado.MemoriaPicoKernelMb = picoKernel
+    agregado.Repeticoes = repeticoes
+    agregado.Aquecimento = aquecimento
+    agregado.Estatisticas = &estatisticas
+    agregado.Amostras = amostras
+    return agregado, nil
+}
+
+// somarErros acrescenta as contagens de parcial a total, que e criado quando
+// ainda nao ha nenhuma.
+func somarErros(total, parcial map[string]int64) map[string]int64 {
+    for tipo, quantidade := range parcial {
+        if total == nil {
+            total = map[string]int64{}
+        }
+        total[tipo] += quantidade
+    }
+    return total
+}
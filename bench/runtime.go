@@ -0,0 +1,148 @@
+package bench
+
+import (
+    "math"
+    "runtime/metrics"
+)
+
+// FaixaHistograma e um balde de histograma do runtime com limites em ms.
+type FaixaHistograma struct {
+    InferiorMs float64 `json:"inferior_ms"`
+    SuperiorMs float64 `json:"superior_ms"`
+    Contagem   uint64  `json:"contagem"`
+}
+
+type PercentisLatencia struct {
+    P50Us float64 `json:"p50_us"`
+    P90Us float64 `json:"p90_us"`
+    P99Us float64 `json:"p99_us"`
+    MaxUs float64 `json:"max_us"`
+}
+
+// MetricasRuntime traz a diferenca entre as leituras de runtime/metrics do
+// inicio e do fim da regiao medida. Percentis e totais derivados de
+// histogramas sao aproximados pelos limites dos baldes.
+type MetricasRuntime struct {
+    CiclosGC            uint64             `json:"ciclos_gc"`
+    PausaTotalMs        float64            `json:"pausa_stw_total_ms"`
+    HistogramaPausas    []FaixaHistograma  `json:"histograma_pausas,omitempty"`
+    BytesAlocados       uint64             `json:"bytes_alocados"`
+    ObjetosAlocados     uint64             `json:"objetos_alocados"`
+    LatenciaEscalonador *PercentisLatencia `json:"latencia_escalonador,omitempty"`
+}
+
+const (
+    metricaCiclosGC      = "/gc/cycles/total:gc-cycles"
+    metricaBytesAlocados = "/gc/heap/allocs:bytes"
+    metricaObjAlocados   = "/gc/heap/allocs:objects"
+    metricaLatencias     = "/sched/latencies:seconds"
+)
+
+// metricaPausas escolhe o histograma de pausas STW do GC: o nome atual ou,
+// em versoes antigas do Go, /gc/pauses:seconds.
+var metricaPausas = func() string {
+    for _, descricao := range metrics.All() {
+        if descricao.Name == "/sched/pauses/total/gc:seconds" {
+            return descricao.Name
+        }
+    }
+    return "/gc/pauses:seconds"
+}()
+
+func lerMetricasRuntime() []metrics.Sample {
+    amostras := []metrics.Sample{
+        {Name: metricaCiclosGC},
+        {Name: metricaPausas},
+        {Name: metricaBytesAlocados},
+        {Name: metricaObjAlocados},
+        {Name: metricaLatencias},
+    }
+    metrics.Read(amostras)
+    return amostras
+}
+
+// calcularMetricasRuntime subtrai duas leituras feitas por lerMetricasRuntime.
+func calcularMetricasRuntime(inicio, fim []metrics.Sample) *MetricasRuntime {
+    if len(inicio) != len(fim) {
+        return nil
+    }
+    resultado := &MetricasRuntime{}
+    for indice := range fim {
+        antes, depois := inicio[indice].Value, fim[indice].Value
+        if antes.Kind() != depois.Kind() {
+            continue
+        }
+        switch depois.Kind() {
+        case metrics.KindUint64:
+            delta := depois.Uint64() - antes.Uint64()
+            switch fim[indice].Name {
+            case metricaCiclosGC:
+                resultado.CiclosGC = delta
+            case metricaBytesAlocados:
+                resultado.BytesAlocados = delta
+            case metricaObjAlocados:
+                resultado.ObjetosAlocados = delta
+            }
+        case metrics.KindFloat64Histogram:
+            faixas := subtrairHistograma(antes.Float64Histogram(), depois.Float64Histogram())
+            switch fim[indice].Name {
+            case metricaPausas:
+                resultado.HistogramaPausas = faixas
+                for _, faixa := range faixas {
+                    resultado.PausaTotalMs += float64(faixa.Contagem) * (faixa.InferiorMs + faixa.SuperiorMs) / 2
+                }
+            case metricaLatencias:
+                if len(faixas) > 0 {
+                    resultado.LatenciaEscalonador = &PercentisLatencia{
+                        P50Us: percentilHistograma(faixas, 0.50) * 1000,
+                        P90Us: percentilHistograma(faixas, 0.90) * 1000,
+                        P99Us: percentilHistograma(faixas, 0.99) * 1000,
+                        MaxUs: faixas[len(faixas)-1].SuperiorMs * 1000,
+                    }
+                }
+            }
+        }
+    }
+    return resultado
+}
+
+// subtrairHistograma devolve os baldes nao vazios de fim - inicio. Limites
+// infinitos sao trocados pelo limite finito do mesmo balde.
+func subtrairHistograma(inicio, fim *metrics.Float64Histogram) []FaixaHistograma {
+    var faixas []FaixaHistograma
+    for indice, contagem := range fim.Counts {
+        if indice < len(inicio.Counts) {
+            contagem -= inicio.Counts[indice]
+        }
+        if contagem == 0 {
+            continue
+        }
+        inferior, superior := fim.Buckets[indice], fim.Buckets[indice+1]
+        if math.IsInf(inferior, -1) {
+            inferior = superior
+        }
+        if math.IsInf(superior, 1) {
+            superior = inferior
+        }
+        faixas = append(faixas, FaixaHistograma{InferiorMs: inferior * 1000, SuperiorMs: superior * 1000, Contagem: contagem})
+    }
+    return faixas
+}
+
+// percentilHistograma devolve o limite superior (ms) do balde que contem o
+// quantil q.
+func percentilHistograma(faixas []FaixaHistograma, q float64) float64 {
+    total := uint64(0)
+    for _, faixa := range faixas {
+        total += faixa.Contagem
+    }
+    alvo := uint64(math.Ceil(q * float64(total)))
+    acumulado := uint64(0)
+    for _, faixa := range faixas {
+        acumulado += faixa.Contagem
+        if acumulado >= alvo {
+            return faixa.SuperiorMs
+        }
+    }
+    return faixas[len(faixas)-1].SuperiorMs
+}
@@ -0,0 +1,43 @@
+package bench
+
+import (
+    "reflect"
+    "strings"
+)
+
+// CampoTabular e uma coluna escalar de MetricasBenchmark, nomeada pela tag
+// JSON do campo. Objetos e listas aninhados ficam de fora.
+type CampoTabular struct {
+    Nome   string
+    indice int
+}
+
+var camposTabulares = descobrirCamposTabulares()
+
+func descobrirCamposTabulares() []CampoTabular {
+    tipo := reflect.TypeOf(MetricasBenchmark{})
+    var campos []CampoTabular
+    for indice := 0; indice < tipo.NumField(); indice++ {
+        campo := tipo.Field(indice)
+        nome, _, _ := strings.Cut(campo.Tag.Get("json"), ",")
+        if nome == "" || nome == "-" {
+            continue
+        }
+        switch campo.Type.Kind() {
+        case reflect.String, reflect.Bool,
+            reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+            reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+            reflect.Float32, reflect.Float64:
+            campos = append(campos, CampoTabular{Nome: nome, indice: indice})
+        }
+    }
+    return campos
+}
+
+func CamposTabulares() []CampoTabular {
+    return append([]CampoTabular(nil), camposTabulares...)
+}
+
+func (campo CampoTabular) Valor(metricas MetricasBenchmark) any {
+    return reflect.ValueOf(metricas).Field(campo.indice).Interface()
+}
@@ -0,0 +1,104 @@
+package bench
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// ticksPorSegundo e o USER_HZ usado pelo kernel em /proc/<pid>/stat, fixo em
+// 100 na ABI do Linux para o espaco de usuario.
+const ticksPorSegundo = 100.0
+
+type TempoThread struct {
+    Tid       int     `json:"tid"`
+    UsuarioMs float64 `json:"usuario_ms"`
+    SistemaMs float64 `json:"sistema_ms"`
+}
+
+// CpuPorThread reparte o tempo de CPU da regiao medida entre as threads do
+// sistema. Desbalanceamento e o maior tempo total (usuario + sistema) de uma
+// thread dividido pela media; 1 indica carga perfeitamente distribuida.
+type CpuPorThread struct {
+    Threads          []TempoThread `json:"threads"`
+    Desbalanceamento float64       `json:"desbalanceamento"`
+}
+
+type ticksThread struct {
+    usuario uint64
+    sistema uint64
+}
+
+// lerTempoThreads le utime e stime (em ticks) de cada thread listada em
+// /proc/self/task. Devolve nil quando /proc nao esta disponivel.
+func lerTempoThreads() map[int]ticksThread {
+    entradas, err := os.ReadDir("/proc/self/task")
+    if err != nil {
+        return nil
+    }
+    tempos := make(map[int]ticksThread, len(entradas))
+    for _, entrada := range entradas {
+        tid, err := strconv.Atoi(entrada.Name())
+        if err != nil {
+            continue
+        }
+        dados, err := os.ReadFile(filepath.Join("/proc/self/task", entrada.Name(), "stat"))
+        if err != nil {
+            continue // a thread terminou durante a leitura
+        }
+        // O nome do comando pode conter espacos; os campos seguintes comecam
+        // apos o ultimo ')'. utime e stime sao os campos 14 e 15 do arquivo,
+        // ou seja, os indices 11 e 12 a partir do estado.
+        texto := string(dados)
+        fimComando := strings.LastIndexByte(texto, ')')
+        if fimComando < 0 {
+            continue
+        }
+        campos := strings.Fields(texto[fimComando+1:])
+        if len(campos) < 13 {
+            continue
+        }
+        usuario, errUsuario := strconv.ParseUint(campos[11], 10, 64)
+        sistema, errSistema := strconv.ParseUint(campos[12], 10, 64)
+        if errUsuario != nil || errSistema != nil {
+            continue
+        }
+        tempos[tid] = ticksThread{usuario: usuario, sistema: sistema}
+    }
+    return tempos
+}
+
+// calcularCpuPorThread subtrai as duas leituras. Threads criadas durante a
+// regiao medida partem de zero; as que terminaram antes do fim nao aparecem.
+// Apenas threads que consumiram CPU entram no relatorio e no desbalanceamento.
+func calcularCpuPorThread(inicio, fim map[int]ticksThread) *CpuPorThread {
+    if fim == nil {
+        return nil
+    }
+    relatorio := &CpuPorThread{Threads: []TempoThread{}}
+    maiorMs, somaMs := 0.0, 0.0
+    for tid, final := range fim {
+        anterior := inicio[tid]
+        if final.usuario < anterior.usuario || final.sistema < anterior.sistema {
+            continue // tid reaproveitado por outra thread
+        }
+        usuarioMs := float64(final.usuario-anterior.usuario) * 1000.0 / ticksPorSegundo
+        sistemaMs := float64(final.sistema-anterior.sistema) * 1000.0 / ticksPorSegundo
+        totalMs := usuarioMs + sistemaMs
+        if totalMs == 0 {
+            continue
+        }
+        relatorio.Threads = append(relatorio.Threads, TempoThread{Tid: tid, UsuarioMs: usuarioMs, SistemaMs: sistemaMs})
+        somaMs += totalMs
+        if totalMs > maiorMs {
+            maiorMs = totalMs
+        }
+    }
+    sort.Slice(relatorio.Threads, func(i, j int) bool { return relatorio.Threads[i].Tid < relatorio.Threads[j].Tid })
+    if len(relatorio.Threads) > 0 {
+        relatorio.Desbalanceamento = maiorMs / (somaMs / float64(len(relatorio.Threads)))
+    }
+    return relatorio
+}
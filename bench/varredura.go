@@ -0,0 +1,136 @@
+package bench
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+)
+
+// InterpretarListaThreads aceita uma lista separada por virgulas cujos itens
+// sao numeros ("4") ou intervalos inclusivos ("1-12", "2-16:2" com passo).
+func InterpretarListaThreads(texto string) ([]int, error) {
+    return interpretarListaInteiros(texto, 1, "numero de threads")
+}
+
+// interpretarListaInteiros le listas no formato de InterpretarListaThreads,
+// rejeitando valores menores que minimo, e devolve os valores ordenados e sem
+// repeticao.
+func interpretarListaInteiros(texto string, minimo int, descricao string) ([]int, error) {
+    vistos := map[int]bool{}
+    var valores []int
+    for _, item := range strings.Split(texto, ",") {
+        item = strings.TrimSpace(item)
+        if item == "" {
+            continue
+        }
+        inicio, fim, passo := 0, 0, 1
+        intervalo := item
+        if antes, depois, ok := strings.Cut(item, ":"); ok {
+            valor, err := strconv.Atoi(depois)
+            if err != nil || valor < 1 {
+                return nil, fmt.Errorf("passo invalido em %q", item)
+            }
+            intervalo, passo = antes, valor
+        }
+        if antes, depois, ok := strings.Cut(intervalo, "-"); ok {
+            var errInicio, errFim error
+            inicio, errInicio = strconv.Atoi(antes)
+            fim, errFim = strconv.Atoi(depois)
+            if errInicio != nil || errFim != nil || fim < inicio {
+                return nil, fmt.Errorf("intervalo invalido %q", item)
+            }
+        } else {
+            valor, err := strconv.Atoi(intervalo)
+            if err != nil {
+                return nil, fmt.Errorf("%s invalido %q", descricao, item)
+            }
+            inicio, fim = valor, valor
+        }
+        if inicio < minimo {
+            return nil, fmt.Errorf("%s deve ser ao menos %d em %q", descricao, minimo, item)
+        }
+        for valor := inicio; valor <= fim; valor += passo {
+            if !vistos[valor] {
+                vistos[valor] = true
+                valores = append(valores, valor)
+            }
+        }
+    }
+    if len(valores) == 0 {
+        return nil, fmt.Errorf("nenhum %s em %q", descricao, texto)
+    }
+    sort.Ints(valores)
+    return valores, nil
+}
+
+// CalcularSpeedup devolve o speedup e a eficiencia paralela de uma execucao
+// de tempoMs com threads threads em relacao a uma referencia que levou
+// tempoReferenciaMs com threadsReferencia threads. A eficiencia e
+// speedup*threadsReferencia/threads, de modo que o ponto de referencia tem
+// eficiencia 1 mesmo quando nao roda com uma thread.
+func CalcularSpeedup(tempoMs float64, threads int, tempoReferenciaMs float64, threadsReferencia int) (speedup, eficiencia float64) {
+    if tempoMs <= 0 || tempoReferenciaMs <= 0 {
+        return 0, 0
+    }
+    speedup = tempoReferenciaMs / tempoMs
+    if threads > 0 && threadsReferencia > 0 {
+        eficiencia = speedup * float64(threadsReferencia) / float64(threads)
+    }
+    return speedup, eficiencia
+}
+
+// AplicarSpeedup preenche speedup e eficiencia de metricas com
+// CalcularSpeedup, em relacao a execucao de referencia.
+func AplicarSpeedup(metricas *MetricasBenchmark, tempoReferenciaMs float64, threadsReferencia int) {
+    if metricas.ParedeMs <= 0 || tempoReferenciaMs <= 0 {
+        return
+    }
+    metricas.Speedup, metricas.Eficiencia = CalcularSpeedup(metricas.ParedeMs, metricas.Threads, tempoReferenciaMs, threadsReferencia)
+}
+
+// VarrerThreads executa o benchmark para cada quantidade de threads, sempre
+// incluindo a execucao com uma thread. A primeira execucao e a referencia do
+// speedup, pelas threads que o benchmark informa ter usado: pc e phil exigem
+// ao menos duas, e nelas a referencia fica em 2 threads. aoConcluir recebe
+// cada ponto assim que ele termina. Um ponto com status timeout encerra a
+// varredura.
+func VarrerThreads(threads []int, executar func(threads int) (MetricasBenchmark, error), aoConcluir func(MetricasBenchmark) error) ([]MetricasBenchmark, error) {
+    if len(threads) == 0 || threads[0] != 1 {
+        threads = append([]int{1}, threads...)
+    }
+    pontos := make([]MetricasBenchmark, 0, len(threads))
+    tempoReferencia := 0.0
+    threadsReferencia := 0
+    for _, quantidade := range threads {
+        metricas, err := executar(quantidade)
+        if err != nil {
+            return pontos, err
+        }
+        if len(pontos) == 0 {
+            tempoReferencia, threadsReferencia = metricas.ParedeMs, metricas.Threads
+        }
+        AplicarSpeedup(&metricas, tempoReferencia, threadsReferencia)
+        pontos = append(pontos, metricas)
+        if aoConcluir != nil {
+            if err := aoConcluir(metricas); err != nil {
+                return pontos, err
+            }
+        }
+        if metricas.Status == StatusTimeout {
+            break
+        }
+    }
+    return pontos, nil
+}
+
+func EscreverTabelaVarredura(saida io.Writer, pontos []MetricasBenchmark) error {
+    tabela := tabwriter.NewWriter(saida, 0, 4, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintln(tabela, "problema\ttamanho\tthreads\ttempo_ms\tspeedup\teficiencia\t")
+    for _, ponto := range pontos {
+        fmt.Fprintf(tabela, "%s\t%d\t%d\t%.3f\t%.3f\t%.3f\t\n", ponto.Problema, ponto.Tamanho, ponto.Threads, ponto.ParedeMs, ponto.Speedup, ponto.Eficiencia)
+    }
+    return tabela.Flush()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+    "os"
+
+    "tcc-benchmarks/internal/cli"
+)
+
+func main() {
+    os.Exit(cli.Main(os.Args[1:]))
+}
@@ -0,0 +1,315 @@
+package cli
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "tcc-benchmarks/bench"
+)
+
+// planoCampanha e o arquivo lido por benchctl campaign. Parametros vale para
+// todos os benchmarks e por_benchmark acrescenta ou substitui eixos de um
+// benchmark especifico; cada chave e o nome de uma flag de benchctl run e
+// cada valor, a lista de valores do eixo. Sem benchmarks, o plano cobre todos
+// os registrados. Nao ha eixo de linguagem: so os benchmarks em Go do
+// benchctl entram na campanha.
+type planoCampanha struct {
+    Nome         string                                  `json:"nome"`
+    Benchmarks   []string                                `json:"benchmarks"`
+    Parametros   map[string][]json.RawMessage            `json:"parametros"`
+    PorBenchmark map[string]map[string][]json.RawMessage `json:"por_benchmark"`
+    Repeticoes   int                                     `json:"repeticoes"`
+    Aquecimento  int                                     `json:"aquecimento"`
+    Ambiente     map[string]string                       `json:"ambiente"`
+    Saidas       []destinoCampanha                       `json:"saidas"`
+}
+
+// destinoCampanha e um arquivo de resultados; arquivo vazio ou "-" e a saida
+// padrao. Formato vazio e jsonl.
+type destinoCampanha struct {
+    Arquivo string `json:"arquivo"`
+    Formato string `json:"formato"`
+}
+
+type pontoCampanha struct {
+    id         string
+    benchmark  string
+    argumentos []string
+}
+
+// formatosCampanha sao os formatos que gravam cada resultado assim que ele e
+// emitido; json e table acumulam tudo ate o fim e perderiam a campanha
+// inteira se ela fosse interrompida.
+var formatosCampanha = []string{"jsonl", "csv"}
+
+// flagsReservadas sao controladas pela propria campanha.
+var flagsReservadas = map[string]bool{"format": true, "out": true, "run-id": true, "reps": true, "warmup": true}
+
+func carregarPlano(caminho string) (*planoCampanha, error) {
+    dados, err := os.ReadFile(caminho)
+    if err != nil {
+        return nil, err
+    }
+    plano := &planoCampanha{Repeticoes: 1}
+    decodificador := json.NewDecoder(bytes.NewReader(dados))
+    decodificador.DisallowUnknownFields()
+    if err := decodificador.Decode(plano); err != nil {
+        return nil, fmt.Errorf("%s: %w", caminho, err)
+    }
+    if plano.Nome == "" {
+        plano.Nome = strings.TrimSuffix(filepath.Base(caminho), filepath.Ext(caminho))
+    }
+    if len(plano.Benchmarks) == 0 {
+        plano.Benchmarks = bench.NomesBenchmarks()
+    }
+    if len(plano.Saidas) == 0 {
+        plano.Saidas = []destinoCampanha{{Formato: "jsonl"}}
+    }
+    for indice, destino := range plano.Saidas {
+        if destino.Formato == "" {
+            plano.Saidas[indice].Formato = "jsonl"
+        } else if !slices.Contains(formatosCampanha, destino.Formato) {
+            return nil, fmt.Errorf("%s: formato %q em saidas nao grava os resultados a cada ponto (use %s)", caminho, destino.Formato, strings.Join(formatosCampanha, " ou "))
+        }
+    }
+    return plano, nil
+}
+
+// valorFlag converte um valor JSON do plano no texto passado a flag: strings
+// sem aspas, numeros e booleanos como escritos.
+func valorFlag(bruto json.RawMessage) string {
+    var texto string
+    if err := json.Unmarshal(bruto, &texto); err == nil {
+        return texto
+    }
+    return strings.TrimSpace(string(bruto))
+}
+
+// expandirPlano gera o produto cartesiano dos eixos de cada benchmark, na
+// ordem dos benchmarks do plano e das flags em ordem alfabetica. Cada ponto e
+// validado contra as flags do benchmark antes de qualquer execucao.
+func expandirPlano(plano *planoCampanha, idCampanha string) ([]pontoCampanha, error) {
+    var pontos []pontoCampanha
+    for _, nome := range plano.Benchmarks {
+        escolhido, ok := bench.NovoBenchmark(nome)
+        if !ok {
+            return nil, fmt.Errorf("benchmark desconhecido: %s (disponiveis: %s)", nome, strings.Join(bench.NomesBenchmarks(), ", "))
+        }
+        eixos := map[string][]string{}
+        for _, grade := range []map[string][]json.RawMessage{plano.Parametros, plano.PorBenchmark[nome]} {
+            for flagNome, valores := range grade {
+                textos := make([]string, 0, len(valores))
+                for _, valor := range valores {
+                    textos = append(textos, valorFlag(valor))
+                }
+                eixos[flagNome] = textos
+            }
+        }
+        nomesEixos := make([]string, 0, len(eixos))
+        for flagNome, valores := range eixos {
+            if flagsReservadas[flagNome] {
+                return nil, fmt.Errorf("%s: a flag --%s e definida pela campanha", nome, flagNome)
+            }
+            if len(valores) == 0 {
+                return nil, fmt.Errorf("%s: eixo %s sem valores", nome, flagNome)
+            }
+            nomesEixos = append(nomesEixos, flagNome)
+        }
+        sort.Strings(nomesEixos)
+
+        validacao := flag.NewFlagSet(nome, flag.ContinueOnError)
+        registrarParametros(escolhido, validacao)
+        registrarFlagsComuns(validacao)
+        for _, flagNome := range nomesEixos {
+            if validacao.Lookup(flagNome) == nil {
+                return nil, fmt.Errorf("%s: flag desconhecida --%s", nome, flagNome)
+            }
+            for _, valor := range eixos[flagNome] {
+                if err := validacao.Set(flagNome, valor); err != nil {
+                    return nil, fmt.Errorf("%s: valor invalido %q para --%s: %w", nome, valor, flagNome, err)
+                }
+            }
+        }
+
+        indices := make([]int, len(nomesEixos))
+        for {
+            argumentos := []string{"--reps", strconv.Itoa(plano.Repeticoes), "--warmup", strconv.Itoa(plano.Aquecimento)}
+            for posicao, flagNome := range nomesEixos {
+                argumentos = append(argumentos, "--"+flagNome+"="+eixos[flagNome][indices[posicao]])
+            }
+            id := fmt.Sprintf("%s-%04d", idCampanha, len(pontos)+1)
+            pontos = append(pontos, pontoCampanha{id: id, benchmark: nome, argumentos: append(argumentos, "--run-id", id)})
+            posicao := len(indices) - 1
+            for ; posicao >= 0; posicao-- {
+                indices[posicao]++
+                if indices[posicao] < len(eixos[nomesEixos[posicao]]) {
+                    break
+                }
+                indices[posicao] = 0
+            }
+            if posicao < 0 {
+                break
+            }
+        }
+    }
+    return pontos, nil
+}
+
+// executarPonto roda o ponto em um processo filho (para que as variaveis de
+// ambiente do plano, como GOGC e GOMAXPROCS, valham desde o inicio do
+// runtime) e entrega cada resultado a aoConcluir assim que ele e emitido.
+func executarPonto(executavel string, ambiente []string, ponto pontoCampanha, aoConcluir func(bench.MetricasBenchmark) error) error {
+    argumentos := append([]string{"run", ponto.benchmark, "--format", "jsonl"}, ponto.argumentos...)
+    comando := exec.Command(executavel, argumentos...)
+    comando.Env = ambiente
+    comando.Stderr = os.Stderr
+    saida, err := comando.StdoutPipe()
+    if err != nil {
+        return err
+    }
+    if err := comando.Start(); err != nil {
+        return err
+    }
+    leitor := bufio.NewScanner(saida)
+    leitor.Buffer(make([]byte, 0, 1<<20), 64<<20)
+    var falha error
+    for leitor.Scan() {
+        linha := leitor.Bytes()
+        var relato struct {
+            Erro *bench.ErroBenchmark `json:"erro"`
+        }
+        if json.Unmarshal(linha, &relato) == nil && relato.Erro != nil {
+            falha = relato.Erro
+            continue
+        }
+        var metricas bench.MetricasBenchmark
+        if err := json.Unmarshal(linha, &metricas); err != nil {
+            falha = fmt.Errorf("resultado invalido: %w", err)
+            continue
+        }
+        if err := aoConcluir(metricas); err != nil && falha == nil {
+            falha = err
+        }
+    }
+    if err := leitor.Err(); err != nil && falha == nil {
+        falha = err
+    }
+    if err := comando.Wait(); err != nil && falha == nil {
+        var saidaProcesso *exec.ExitError
+        if !errors.As(err, &saidaProcesso) || saidaProcesso.ExitCode() != codigoTimeout {
+            falha = err
+        }
+    }
+    return falha
+}
+
+func executarCampaign(args []string) int {
+    flags := flag.NewFlagSet("campaign", flag.ContinueOnError)
+    simular := flags.Bool("dry-run", false, "apenas lista os pontos da campanha, sem executar")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "uso: benchctl campaign [--dry-run] plano.json")
+        flags.PrintDefaults()
+    }
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return 0
+        }
+        return 2
+    }
+    if flags.NArg() != 1 {
+        flags.Usage()
+        return 2
+    }
+    plano, err := carregarPlano(flags.Arg(0))
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"plano": flags.Arg(0)}))
+    }
+    idCampanha := plano.Nome + "-" + time.Now().Format("20060102T150405")
+    pontos, err := expandirPlano(plano, idCampanha)
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"plano": flags.Arg(0)}))
+    }
+    if *simular {
+        for _, ponto := range pontos {
+            fmt.Printf("%s\tbenchctl run %s %s\n", ponto.id, ponto.benchmark, strings.Join(ponto.argumentos, " "))
+        }
+        return 0
+    }
+
+    executavel, err := os.Executable()
+    if err != nil {
+        return reportarErro(err)
+    }
+    ambiente := os.Environ()
+    nomesAmbiente := make([]string, 0, len(plano.Ambiente))
+    for nome := range plano.Ambiente {
+        nomesAmbiente = append(nomesAmbiente, nome)
+    }
+    sort.Strings(nomesAmbiente)
+    for _, nome := range nomesAmbiente {
+        ambiente = append(ambiente, nome+"="+plano.Ambiente[nome])
+    }
+
+    emissores := make([]bench.Emissor, 0, len(plano.Saidas))
+    var fechamentos []func() error
+    defer func() {
+        for _, fechar := range fechamentos {
+            if err := fechar(); err != nil {
+                reportarErroEm(os.Stderr, err)
+            }
+        }
+    }()
+    for _, destino := range plano.Saidas {
+        arquivo := destino.Arquivo
+        if arquivo == "-" {
+            arquivo = ""
+        }
+        emissor, fechar, err := abrirSaida(&opcoesExecucao{formato: destino.Formato, arquivoSaida: arquivo})
+        if err != nil {
+            return reportarErro(err)
+        }
+        emissores = append(emissores, emissor)
+        fechamentos = append(fechamentos, fechar)
+    }
+    emitir := func(metricas bench.MetricasBenchmark) error {
+        for _, emissor := range emissores {
+            if err := emissor.Emitir(metricas); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+
+    falhas := 0
+    for indice, ponto := range pontos {
+        fmt.Fprintf(os.Stderr, "[%d/%d] %s: %s %s\n", indice+1, len(pontos), ponto.id, ponto.benchmark, strings.Join(ponto.argumentos, " "))
+        if err := executarPonto(executavel, ambiente, ponto, emitir); err != nil {
+            falhas++
+            // Vai para a saida de erro: a saida padrao pode ser um dos
+            // destinos dos resultados.
+            estruturado := bench.ClassificarErro(err)
+            contexto := map[string]string{"ponto": ponto.id}
+            for chave, valor := range estruturado.Contexto {
+                contexto[chave] = valor
+            }
+            reportarErroEm(os.Stderr, bench.NovoErro(estruturado.Codigo, err, contexto))
+        }
+    }
+    fmt.Fprintf(os.Stderr, "campanha %s: %d pontos, %d falhas\n", idCampanha, len(pontos), falhas)
+    if falhas > 0 {
+        return 1
+    }
+    return 0
+}
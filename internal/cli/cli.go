@@ -0,0 +1,372 @@
+// Package cli implementa a linha de comando comum ao benchctl e aos
+// executaveis individuais de cada benchmark.
+package cli
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "strconv"
+    "strings"
+    "text/tabwriter"
+    "time"
+
+    "tcc-benchmarks/bench"
+    _ "tcc-benchmarks/problemas/todos"
+)
+
+type opcoesExecucao struct {
+    repeticoes       int
+    aquecimento      int
+    varreduraThreads string
+    formato          string
+    arquivoSaida     string
+    cpus             string
+    vinculo          string
+    contadoresPerf   bool
+    intervaloAmostra time.Duration
+    arquivoAmostra   string
+    timeout          time.Duration
+    idExecucao       string
+    perfilCpu        string
+    perfilMemoria    string
+    perfilBloqueio   string
+    perfilMutex      string
+    rastro           string
+    verificar        bool
+}
+
+func registrarFlagsComuns(flags *flag.FlagSet) *opcoesExecucao {
+    opcoes := &opcoesExecucao{}
+    flags.IntVar(&opcoes.repeticoes, "reps", bench.ObterIntEnv("BENCH_REPS", 1), "numero de repeticoes medidas")
+    flags.IntVar(&opcoes.aquecimento, "warmup", bench.ObterIntEnv("BENCH_WARMUP", 0), "numero de execucoes de aquecimento descartadas")
+    flags.StringVar(&opcoes.varreduraThreads, "sweep-threads", bench.ObterStringEnv("BENCH_SWEEP_THREADS", ""), "lista/intervalo de threads a varrer (ex.: 1,2,4,8 ou 1-12), com speedup e eficiencia")
+    flags.StringVar(&opcoes.formato, "format", bench.ObterStringEnv("BENCH_FORMAT", "json"), "formato de saida: "+strings.Join(bench.FormatosSaida, "|"))
+    flags.StringVar(&opcoes.arquivoSaida, "out", bench.ObterStringEnv("BENCH_OUT", ""), "arquivo onde os resultados sao acrescentados (padrao: saida padrao)")
+    flags.StringVar(&opcoes.cpus, "cpus", bench.ObterStringEnv("BENCH_CPUS", ""), "CPUs onde o processo pode executar (ex.: 0-11 ou 0,2,4); padrao: mascara herdada")
+    flags.StringVar(&opcoes.vinculo, "bind", bench.ObterStringEnv("BENCH_BIND", "none"), "fixacao dos workers em CPUs: "+strings.Join(bench.PoliticasVinculo, "|"))
+    flags.BoolVar(&opcoes.contadoresPerf, "perf", bench.ObterIntEnv("BENCH_PERF", 0) != 0, "coleta contadores de hardware (ciclos, instrucoes, LLC, desvios) via perf_event_open")
+    flags.DurationVar(&opcoes.intervaloAmostra, "sample-interval", obterDuracaoEnv("BENCH_SAMPLE_INTERVAL", 0), "intervalo do amostrador de CPU, memoria, gorrotinas, threads e fila durante a regiao medida (ex.: 10ms); 0 desliga")
+    flags.StringVar(&opcoes.arquivoAmostra, "sample-out", bench.ObterStringEnv("BENCH_SAMPLE_OUT", ""), "arquivo JSONL onde os pontos do amostrador sao acrescentados (padrao: no proprio resultado)")
+    flags.DurationVar(&opcoes.timeout, "timeout", obterDuracaoEnv("BENCH_TIMEOUT", 0), "tempo maximo da invocacao (ex.: 30s); ao expirar os workers param e o resultado parcial sai com status timeout; 0 desliga")
+    flags.StringVar(&opcoes.idExecucao, "run-id", bench.ObterStringEnv("BENCH_RUN_ID", ""), "identificador gravado em id_execucao de cada resultado")
+    flags.StringVar(&opcoes.perfilCpu, "cpuprofile", bench.ObterStringEnv("BENCH_CPUPROFILE", ""), "grava o perfil de CPU da regiao medida neste arquivo")
+    flags.StringVar(&opcoes.perfilMemoria, "memprofile", bench.ObterStringEnv("BENCH_MEMPROFILE", ""), "grava o perfil de memoria ao fim da regiao medida (e um retrato inicial em <arquivo>.base)")
+    flags.StringVar(&opcoes.perfilBloqueio, "blockprofile", bench.ObterStringEnv("BENCH_BLOCKPROFILE", ""), "grava o perfil de bloqueio da regiao medida (e <arquivo>.base)")
+    flags.StringVar(&opcoes.perfilMutex, "mutexprofile", bench.ObterStringEnv("BENCH_MUTEXPROFILE", ""), "grava o perfil de contencao de mutex da regiao medida (e <arquivo>.base)")
+    flags.StringVar(&opcoes.rastro, "trace", bench.ObterStringEnv("BENCH_TRACE", ""), "grava o rastro de execucao (go tool trace) da regiao medida")
+    flags.BoolVar(&opcoes.verificar, "verify", bench.ObterIntEnv("BENCH_VERIFY", 0) != 0, "recalcula o checksum com a implementacao sequencial de referencia e falha (codigo 4) se divergir")
+    return opcoes
+}
+
+// obterDuracaoEnv le uma duracao (ex.: "10ms") da variavel nome ou devolve padrao.
+func obterDuracaoEnv(nome string, padrao time.Duration) time.Duration {
+    if duracao, err := time.ParseDuration(bench.ObterStringEnv(nome, "")); err == nil {
+        return duracao
+    }
+    return padrao
+}
+
+// aplicarAfinidade restringe o processo as CPUs de --cpus e ativa a politica
+// de --bind. Sem nenhuma das duas flags a afinidade herdada e mantida e o
+// resultado nao traz o campo afinidade.
+func aplicarAfinidade(opcoes *opcoesExecucao) error {
+    if opcoes.cpus == "" && opcoes.vinculo == "none" {
+        return nil
+    }
+    var cpus []int
+    if opcoes.cpus != "" {
+        lista, err := bench.InterpretarListaCpus(opcoes.cpus)
+        if err != nil {
+            return err
+        }
+        cpus = lista
+    }
+    return bench.ConfigurarAfinidade(cpus, opcoes.vinculo)
+}
+
+// registrarParametros define em flags as flags proprias de b e devolve seus
+// parametros.
+func registrarParametros(b bench.Benchmark, flags *flag.FlagSet) []bench.Parametro {
+    parametros := b.Parametros()
+    for _, parametro := range parametros {
+        parametro.Registrar(flags)
+    }
+    return parametros
+}
+
+type execucaoPreparada struct {
+    flags    *flag.FlagSet
+    opcoes   *opcoesExecucao
+    executar func() (bench.MetricasBenchmark, error)
+    // cancelar libera o contexto de --timeout; deve ser chamada ao terminar.
+    cancelar context.CancelFunc
+}
+
+// Codigos de saida dos benchmarks. codigoRegressao so e usado pelo gate, para
+// que a CI distinga uma regressao de uma falha do proprio gate; codigoTimeout
+// e o mesmo usado pelo timeout(1) do coreutils.
+const (
+    codigoErroInterno    = 1
+    codigoErroUso        = 2
+    codigoErroPreparacao = 3
+    codigoErroKernel     = 4
+    codigoRegressao      = 5
+    codigoTimeout        = 124
+)
+
+// prepararExecucao monta o FlagSet do benchmark com as flags comuns e
+// interpreta args. Quando nao ha o que executar (erro de uso ou --help),
+// devolve nil e o codigo de saida correspondente. Cada resultado registra em
+// parametros os valores das flags proprias do benchmark e, em
+// memoria_baseline_mb, o RSS anterior a preparacao dos dados. O prazo de
+// --timeout comeca a contar aqui e vale para todas as execucoes preparadas.
+func prepararExecucao(nome string, args []string) (*execucaoPreparada, int) {
+    escolhido, ok := bench.NovoBenchmark(nome)
+    if !ok {
+        return nil, reportarErro(bench.NovoErro(bench.ErroUso, fmt.Errorf("benchmark desconhecido: %s", nome), map[string]string{"disponiveis": strings.Join(bench.NomesBenchmarks(), ",")}))
+    }
+    flags := flag.NewFlagSet(escolhido.Nome(), flag.ContinueOnError)
+    parametros := registrarParametros(escolhido, flags)
+    opcoes := registrarFlagsComuns(flags)
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return nil, 0
+        }
+        return nil, reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"benchmark": escolhido.Nome()}))
+    }
+    ctx, cancelar := context.WithCancel(context.Background())
+    if opcoes.timeout > 0 {
+        ctxPrazo, cancelarPrazo := context.WithTimeout(ctx, opcoes.timeout)
+        cancelarPai := cancelar
+        ctx, cancelar = ctxPrazo, func() {
+            cancelarPrazo()
+            cancelarPai()
+        }
+    }
+    executarRegistrando := func() (bench.MetricasBenchmark, error) {
+        memoriaBaseline := bench.MemoriaAtualEmMb()
+        metricas, err := bench.Medir(ctx, escolhido)
+        metricas.MemoriaBaselineMb = memoriaBaseline
+        metricas.IdExecucao = opcoes.idExecucao
+        if err == nil && ctx.Err() != nil {
+            metricas.Status = bench.StatusTimeout
+        }
+        metricas.Parametros = make(map[string]string, len(parametros))
+        for _, parametro := range parametros {
+            metricas.Parametros[parametro.Nome] = parametro.Valor()
+        }
+        return metricas, err
+    }
+    return &execucaoPreparada{flags: flags, opcoes: opcoes, executar: executarRegistrando, cancelar: cancelar}, 0
+}
+
+// ExecutarBenchmark interpreta args como as flags do benchmark indicado, executa
+// o benchmark e devolve o codigo de saida do processo.
+func ExecutarBenchmark(nome string, args []string) int {
+    preparada, codigo := prepararExecucao(nome, args)
+    if preparada == nil {
+        return codigo
+    }
+    defer preparada.cancelar()
+    flags, opcoes, executar := preparada.flags, preparada.opcoes, preparada.executar
+    if err := aplicarAfinidade(opcoes); err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"cpus": opcoes.cpus, "bind": opcoes.vinculo}))
+    }
+    bench.ConfigurarColeta(bench.OpcoesColeta{
+        ContadoresPerf:      opcoes.contadoresPerf,
+        IntervaloAmostragem: opcoes.intervaloAmostra,
+        ArquivoAmostragem:   opcoes.arquivoAmostra,
+        PerfilCpu:           opcoes.perfilCpu,
+        PerfilMemoria:       opcoes.perfilMemoria,
+        PerfilBloqueio:      opcoes.perfilBloqueio,
+        PerfilMutex:         opcoes.perfilMutex,
+        Rastro:              opcoes.rastro,
+        ConferirChecksum:    opcoes.verificar,
+    })
+    emissor, fecharSaida, err := abrirSaida(opcoes)
+    if err != nil {
+        return reportarErro(err)
+    }
+    if opcoes.varreduraThreads != "" {
+        codigo = executarVarredura(flags, opcoes, executar, emissor)
+    } else {
+        codigo = executarUnico(opcoes, executar, emissor)
+    }
+    if err := fecharSaida(); err != nil {
+        return reportarErro(err)
+    }
+    return codigo
+}
+
+// abrirSaida prepara o emissor do formato pedido. Com --out, os resultados sao
+// acrescentados ao arquivo e o cabecalho so e escrito se ele estiver vazio.
+func abrirSaida(opcoes *opcoesExecucao) (bench.Emissor, func() error, error) {
+    var destino io.Writer = os.Stdout
+    fecharArquivo := func() error { return nil }
+    comCabecalho := true
+    if opcoes.arquivoSaida != "" {
+        arquivo, err := os.OpenFile(opcoes.arquivoSaida, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+        if err != nil {
+            return nil, nil, bench.NovoErro(bench.ErroPreparacao, err, map[string]string{"arquivo": opcoes.arquivoSaida})
+        }
+        if informacoes, err := arquivo.Stat(); err == nil && informacoes.Size() > 0 {
+            comCabecalho = false
+        }
+        destino = arquivo
+        fecharArquivo = arquivo.Close
+    }
+    emissor, err := bench.NovoEmissor(opcoes.formato, destino, comCabecalho)
+    if err != nil {
+        fecharArquivo()
+        return nil, nil, bench.NovoErro(bench.ErroUso, err, map[string]string{"format": opcoes.formato})
+    }
+    fechar := func() error {
+        errFinalizar := emissor.Finalizar()
+        if err := fecharArquivo(); err != nil && errFinalizar == nil {
+            return err
+        }
+        return errFinalizar
+    }
+    return emissor, fechar, nil
+}
+
+func executarUnico(opcoes *opcoesExecucao, executar func() (bench.MetricasBenchmark, error), emissor bench.Emissor) int {
+    metricas, err := bench.ExecutarRepeticoes(opcoes.repeticoes, opcoes.aquecimento, executar)
+    if err != nil {
+        return reportarErro(err)
+    }
+    if err := emissor.Emitir(metricas); err != nil {
+        return reportarErro(err)
+    }
+    if metricas.Status == bench.StatusTimeout {
+        return codigoTimeout
+    }
+    return 0
+}
+
+// executarVarredura repete o benchmark para cada quantidade de threads pedida,
+// ajustando a flag --threads do proprio benchmark. Cada ponto vai para o
+// emissor e, fora do formato table, a tabela de speedup/eficiencia vai para a
+// saida de erro.
+func executarVarredura(flags *flag.FlagSet, opcoes *opcoesExecucao, executar func() (bench.MetricasBenchmark, error), emissor bench.Emissor) int {
+    threads, err := bench.InterpretarListaThreads(opcoes.varreduraThreads)
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"sweep-threads": opcoes.varreduraThreads}))
+    }
+    executarComThreads := func(quantidade int) (bench.MetricasBenchmark, error) {
+        if err := flags.Set("threads", strconv.Itoa(quantidade)); err != nil {
+            return bench.MetricasBenchmark{}, err
+        }
+        return bench.ExecutarRepeticoes(opcoes.repeticoes, opcoes.aquecimento, executar)
+    }
+    pontos, err := bench.VarrerThreads(threads, executarComThreads, emissor.Emitir)
+    if err != nil {
+        return reportarErro(err)
+    }
+    if opcoes.formato != "table" {
+        if err := bench.EscreverTabelaVarredura(os.Stderr, pontos); err != nil {
+            return reportarErro(err)
+        }
+    }
+    if len(pontos) > 0 && pontos[len(pontos)-1].Status == bench.StatusTimeout {
+        return codigoTimeout
+    }
+    return 0
+}
+
+// reportarErro escreve na saida padrao {"erro": {codigo, mensagem, contexto}}
+// e devolve o codigo de saida correspondente a classificacao do erro.
+func reportarErro(err error) int {
+    return reportarErroEm(os.Stdout, err)
+}
+
+// reportarErroEm e reportarErro com outro destino, para quando a saida padrao
+// carrega resultados que o objeto de erro nao deve interromper.
+func reportarErroEm(saida io.Writer, err error) int {
+    estruturado := bench.ClassificarErro(err)
+    dados, _ := json.Marshal(map[string]*bench.ErroBenchmark{"erro": estruturado})
+    fmt.Fprintln(saida, string(dados))
+    switch estruturado.Codigo {
+    case bench.ErroUso:
+        return codigoErroUso
+    case bench.ErroPreparacao:
+        return codigoErroPreparacao
+    case bench.ErroKernel:
+        return codigoErroKernel
+    }
+    return codigoErroInterno
+}
+
+func listarBenchmarks(saida io.Writer) {
+    tabela := tabwriter.NewWriter(saida, 0, 4, 2, ' ', 0)
+    for _, nome := range bench.NomesBenchmarks() {
+        atual, _ := bench.NovoBenchmark(nome)
+        fmt.Fprintf(tabela, "%s\t%s\n", atual.Nome(), atual.Descricao())
+        flags := flag.NewFlagSet(atual.Nome(), flag.ContinueOnError)
+        registrarParametros(atual, flags)
+        flags.VisitAll(func(parametro *flag.Flag) {
+            fmt.Fprintf(tabela, "  --%s\tpadrao: %s\t%s\n", parametro.Name, parametro.DefValue, parametro.Usage)
+        })
+    }
+    fmt.Fprintf(tabela, "%s\t%s\n", "(todos)", "flags comuns a qualquer benchmark")
+    comuns := flag.NewFlagSet("comuns", flag.ContinueOnError)
+    registrarFlagsComuns(comuns)
+    comuns.VisitAll(func(parametro *flag.Flag) {
+        fmt.Fprintf(tabela, "  --%s\tpadrao: %s\t%s\n", parametro.Name, parametro.DefValue, parametro.Usage)
+    })
+    tabela.Flush()
+}
+
+func imprimirUso(saida io.Writer) {
+    fmt.Fprintln(saida, "uso:")
+    fmt.Fprintln(saida, "  benchctl run <benchmark> [flags]")
+    fmt.Fprintln(saida, "  benchctl list")
+    fmt.Fprintln(saida, "  benchctl plot [--saida dir] [rotulo=]resultados.jsonl ...")
+    fmt.Fprintln(saida, "  benchctl xlsx [--saida arquivo.xlsx] [rotulo=]resultados.jsonl ...")
+    fmt.Fprintln(saida, "  benchctl compare [flags] antigo.jsonl novo.jsonl")
+    fmt.Fprintln(saida, "  benchctl gate --baseline referencia.jsonl [flags]")
+    fmt.Fprintln(saida, "  benchctl campaign [--dry-run] plano.json")
+    fmt.Fprintf(saida, "benchmarks: %s\n", strings.Join(bench.NomesBenchmarks(), ", "))
+}
+
+// Main executa o benchctl com os argumentos da linha de comando (sem o nome do
+// programa) e devolve o codigo de saida do processo.
+func Main(args []string) int {
+    if len(args) == 0 {
+        imprimirUso(os.Stderr)
+        return 2
+    }
+    switch args[0] {
+    case "run":
+        if len(args) < 2 {
+            imprimirUso(os.Stderr)
+            return 2
+        }
+        return ExecutarBenchmark(args[1], args[2:])
+    case "plot":
+        return executarPlot(args[1:])
+    case "xlsx":
+        return executarXLSX(args[1:])
+    case "compare":
+        return executarCompare(args[1:])
+    case "gate":
+        return executarGate(args[1:])
+    case "campaign":
+        return executarCampaign(args[1:])
+    case "list":
+        listarBenchmarks(os.Stdout)
+        return 0
+    case "help", "-h", "--help":
+        imprimirUso(os.Stdout)
+        return 0
+    default:
+        fmt.Fprintf(os.Stderr, "subcomando desconhecido: %s\n", args[0])
+        imprimirUso(os.Stderr)
+        return 2
+    }
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "tcc-benchmarks/bench"
+    "tcc-benchmarks/relatorio"
+)
+
+func executarCompare(args []string) int {
+    flags := flag.NewFlagSet("compare", flag.ContinueOnError)
+    metrica := flags.String("metric", "tempo_decorrido_ms", "metrica comparada: "+strings.Join(relatorio.MetricasComparaveis, "|"))
+    teste := flags.String("test", "mannwhitney", "teste de significancia: "+strings.Join(relatorio.TestesSignificancia, "|"))
+    alfa := flags.Float64("alpha", 0.05, "nivel de significancia")
+    formato := flags.String("format", "table", "formato de saida: table|jsonl")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "uso: benchctl compare [flags] antigo.jsonl novo.jsonl")
+        flags.PrintDefaults()
+    }
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return 0
+        }
+        return 2
+    }
+    if flags.NArg() != 2 || (*formato != "table" && *formato != "jsonl") {
+        flags.Usage()
+        return 2
+    }
+    antigos, err := relatorio.CarregarResultados(flags.Arg(0))
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"arquivo": flags.Arg(0)}))
+    }
+    novos, err := relatorio.CarregarResultados(flags.Arg(1))
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"arquivo": flags.Arg(1)}))
+    }
+    comparacoes, err := relatorio.Comparar(antigos, novos, *metrica, *teste, *alfa)
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroUso, err, map[string]string{"metric": *metrica, "test": *teste}))
+    }
+    if len(comparacoes) == 0 {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, errors.New("nenhum (problema, tamanho, threads) em comum entre os arquivos"), map[string]string{"antigo": flags.Arg(0), "novo": flags.Arg(1)}))
+    }
+    if *formato == "jsonl" {
+        codificador := json.NewEncoder(os.Stdout)
+        for _, comparacao := range comparacoes {
+            if err := codificador.Encode(comparacao); err != nil {
+                return reportarErroEm(os.Stderr, err)
+            }
+        }
+        return 0
+    }
+    if err := relatorio.EscreverTabelaComparacao(os.Stdout, comparacoes, *metrica); err != nil {
+        return reportarErroEm(os.Stderr, err)
+    }
+    return 0
+}
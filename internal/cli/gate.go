@@ -0,0 +1,128 @@
+package cli
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "slices"
+    "sort"
+    "strconv"
+    "strings"
+
+    "tcc-benchmarks/bench"
+    "tcc-benchmarks/relatorio"
+)
+
+// argumentosReexecucao reconstroi as flags de um resultado da referencia: os
+// parametros gravados, quando existem, e sempre o tamanho e as threads.
+func argumentosReexecucao(metricas bench.MetricasBenchmark) []string {
+    nomes := make([]string, 0, len(metricas.Parametros))
+    for nome := range metricas.Parametros {
+        if nome != "size" && nome != "threads" {
+            nomes = append(nomes, nome)
+        }
+    }
+    sort.Strings(nomes)
+    argumentos := []string{"--size", strconv.Itoa(metricas.Tamanho), "--threads", strconv.Itoa(metricas.Threads)}
+    for _, nome := range nomes {
+        argumentos = append(argumentos, "--"+nome+"="+metricas.Parametros[nome])
+    }
+    return argumentos
+}
+
+func executarGate(args []string) int {
+    flags := flag.NewFlagSet("gate", flag.ContinueOnError)
+    referencia := flags.String("baseline", "", "arquivo de resultados de referencia (obrigatorio)")
+    tolerancia := flags.Float64("tolerance", 10, "aumento maximo aceito no tempo medio, em %")
+    teste := flags.String("test", "mannwhitney", "teste de significancia: "+strings.Join(relatorio.TestesSignificancia, "|"))
+    alfa := flags.Float64("alpha", 0.05, "nivel de significancia exigido para acusar regressao")
+    repeticoes := flags.Int("reps", 5, "repeticoes medidas por ponto")
+    aquecimento := flags.Int("warmup", 1, "execucoes de aquecimento por ponto")
+    somente := flags.String("only", "", "lista de problemas a verificar (padrao: todos os da referencia)")
+    arquivoSaida := flags.String("out", "", "arquivo onde os novos resultados sao acrescentados em JSONL")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "uso: benchctl gate --baseline referencia.jsonl [flags]")
+        flags.PrintDefaults()
+    }
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return 0
+        }
+        return 2
+    }
+    if *referencia == "" || flags.NArg() > 0 || !slices.Contains(relatorio.TestesSignificancia, *teste) {
+        flags.Usage()
+        return 2
+    }
+    problemasSelecionados := map[string]bool{}
+    for _, nome := range strings.Split(*somente, ",") {
+        if nome = strings.TrimSpace(nome); nome != "" {
+            if _, ok := bench.NovoBenchmark(nome); !ok {
+                return reportarErro(bench.NovoErro(bench.ErroUso, fmt.Errorf("benchmark desconhecido em --only: %s", nome), map[string]string{"only": *somente, "disponiveis": strings.Join(bench.NomesBenchmarks(), ",")}))
+            }
+            problemasSelecionados[nome] = true
+        }
+    }
+    resultadosReferencia, err := relatorio.CarregarResultados(*referencia)
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"baseline": *referencia}))
+    }
+    var emissor bench.Emissor
+    fecharSaida := func() error { return nil }
+    if *arquivoSaida != "" {
+        emissor, fecharSaida, err = abrirSaida(&opcoesExecucao{formato: "jsonl", arquivoSaida: *arquivoSaida})
+        if err != nil {
+            return reportarErro(err)
+        }
+    }
+    defer fecharSaida()
+
+    var selecionados []bench.MetricasBenchmark
+    executados := map[relatorio.ChaveComparacao]bool{}
+    var novos []bench.MetricasBenchmark
+    for _, metricas := range resultadosReferencia {
+        if len(problemasSelecionados) > 0 && !problemasSelecionados[metricas.Problema] {
+            continue
+        }
+        selecionados = append(selecionados, metricas)
+        chave := relatorio.ChaveComparacao{Problema: metricas.Problema, Tamanho: metricas.Tamanho, Threads: metricas.Threads}
+        if executados[chave] {
+            continue
+        }
+        executados[chave] = true
+        preparada, codigo := prepararExecucao(metricas.Problema, argumentosReexecucao(metricas))
+        if preparada == nil {
+            return codigo
+        }
+        fmt.Fprintf(os.Stderr, "executando %s tamanho=%d threads=%d\n", chave.Problema, chave.Tamanho, chave.Threads)
+        novo, err := bench.ExecutarRepeticoes(*repeticoes, *aquecimento, preparada.executar)
+        preparada.cancelar()
+        if err != nil {
+            return reportarErro(err)
+        }
+        novos = append(novos, novo)
+        if emissor != nil {
+            if err := emissor.Emitir(novo); err != nil {
+                return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"arquivo": *arquivoSaida}))
+            }
+        }
+    }
+    if len(novos) == 0 {
+        return reportarErro(bench.NovoErro(bench.ErroUso, errors.New("nenhum resultado da referencia foi selecionado"), map[string]string{"baseline": *referencia, "only": *somente}))
+    }
+    comparacoes, err := relatorio.Comparar(selecionados, novos, "tempo_decorrido_ms", *teste, *alfa)
+    if err != nil {
+        return reportarErro(err)
+    }
+    regressoes := relatorio.Regressoes(comparacoes, *tolerancia)
+    if len(regressoes) == 0 {
+        fmt.Printf("ok: %d pontos dentro da tolerancia de %.1f%%\n", len(comparacoes), *tolerancia)
+        return 0
+    }
+    fmt.Printf("regressao: %d de %d pontos ficaram mais de %.1f%% mais lentos\n", len(regressoes), len(comparacoes), *tolerancia)
+    if err := relatorio.EscreverTabelaComparacao(os.Stdout, regressoes, "tempo_decorrido_ms"); err != nil {
+        reportarErroEm(os.Stderr, err)
+    }
+    return codigoRegressao
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+
+    "tcc-benchmarks/bench"
+    "tcc-benchmarks/relatorio"
+)
+
+// interpretarEntradaRotulada separa argumentos "rotulo=arquivo". Sem rotulo,
+// usa o nome do arquivo sem extensao.
+func interpretarEntradaRotulada(argumento string) (string, string) {
+    if rotulo, caminho, ok := strings.Cut(argumento, "="); ok && rotulo != "" && !strings.ContainsRune(rotulo, os.PathSeparator) {
+        return rotulo, caminho
+    }
+    base := filepath.Base(argumento)
+    return strings.TrimSuffix(base, filepath.Ext(base)), argumento
+}
+
+func carregarEntradasRotuladas(argumentos []string) ([]string, [][]bench.MetricasBenchmark, error) {
+    rotulos := make([]string, 0, len(argumentos))
+    resultados := make([][]bench.MetricasBenchmark, 0, len(argumentos))
+    for _, argumento := range argumentos {
+        rotulo, caminho := interpretarEntradaRotulada(argumento)
+        lidos, err := relatorio.CarregarResultados(caminho)
+        if err != nil {
+            return nil, nil, bench.NovoErro(bench.ErroInterno, err, map[string]string{"arquivo": caminho})
+        }
+        rotulos = append(rotulos, rotulo)
+        resultados = append(resultados, lidos)
+    }
+    return rotulos, resultados, nil
+}
+
+func executarPlot(args []string) int {
+    flags := flag.NewFlagSet("plot", flag.ContinueOnError)
+    diretorio := flags.String("saida", ".", "diretorio onde os SVG sao gravados")
+    problema := flags.String("problema", "", "gera apenas os graficos deste problema")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "uso: benchctl plot [flags] [rotulo=]resultados.jsonl ...")
+        flags.PrintDefaults()
+    }
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return 0
+        }
+        return 2
+    }
+    if flags.NArg() == 0 {
+        flags.Usage()
+        return 2
+    }
+    rotulos, resultados, err := carregarEntradasRotuladas(flags.Args())
+    if err != nil {
+        return reportarErro(err)
+    }
+    if err := os.MkdirAll(*diretorio, 0o755); err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"saida": *diretorio}))
+    }
+    graficos, chaves := relatorio.AgruparPorGrafico(rotulos, resultados)
+    gerados := 0
+    for _, chave := range chaves {
+        if *problema != "" && chave.Problema != *problema {
+            continue
+        }
+        for _, tipo := range relatorio.TiposGrafico {
+            caminho := filepath.Join(*diretorio, fmt.Sprintf("%s-%d - Threads x %s.svg", chave.Problema, chave.Tamanho, tipo.Nome()))
+            titulo := fmt.Sprintf("%s (tamanho %d) - Threads x %s", chave.Problema, chave.Tamanho, tipo.Nome())
+            if err := gravarGrafico(caminho, titulo, tipo, graficos[chave]); err != nil {
+                return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"arquivo": caminho}))
+            }
+            fmt.Println(caminho)
+            gerados++
+        }
+    }
+    if gerados == 0 {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, errors.New("nenhum resultado para plotar"), map[string]string{"entradas": strings.Join(flags.Args(), ","), "problema": *problema}))
+    }
+    return 0
+}
+
+func gravarGrafico(caminho, titulo string, tipo relatorio.TipoGrafico, series []relatorio.Serie) error {
+    arquivo, err := os.Create(caminho)
+    if err != nil {
+        return err
+    }
+    if err := relatorio.EscreverGraficoSVG(arquivo, titulo, tipo, series); err != nil {
+        arquivo.Close()
+        return err
+    }
+    return arquivo.Close()
+}
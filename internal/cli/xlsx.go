@@ -0,0 +1,52 @@
+package cli
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "os"
+    "strings"
+
+    "tcc-benchmarks/bench"
+    "tcc-benchmarks/relatorio"
+)
+
+func executarXLSX(args []string) int {
+    flags := flag.NewFlagSet("xlsx", flag.ContinueOnError)
+    destino := flags.String("saida", "Resultados levantados.xlsx", "arquivo .xlsx gerado")
+    flags.Usage = func() {
+        fmt.Fprintln(flags.Output(), "uso: benchctl xlsx [flags] [rotulo=]resultados.jsonl ...")
+        flags.PrintDefaults()
+    }
+    if err := flags.Parse(args); err != nil {
+        if errors.Is(err, flag.ErrHelp) {
+            return 0
+        }
+        return 2
+    }
+    if flags.NArg() == 0 {
+        flags.Usage()
+        return 2
+    }
+    rotulos, resultados, err := carregarEntradasRotuladas(flags.Args())
+    if err != nil {
+        return reportarErro(err)
+    }
+    planilhas := relatorio.MontarPlanilhas(rotulos, resultados)
+    if len(planilhas) == 0 {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, errors.New("nenhum resultado para exportar"), map[string]string{"entradas": strings.Join(flags.Args(), ",")}))
+    }
+    arquivo, err := os.Create(*destino)
+    if err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"saida": *destino}))
+    }
+    if err := relatorio.EscreverXLSX(arquivo, planilhas); err != nil {
+        arquivo.Close()
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"saida": *destino}))
+    }
+    if err := arquivo.Close(); err != nil {
+        return reportarErro(bench.NovoErro(bench.ErroInterno, err, map[string]string{"saida": *destino}))
+    }
+    fmt.Println(*destino)
+    return 0
+}
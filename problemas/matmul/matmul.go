@@ -0,0 +1,245 @@
+package matmul
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "math/rand"
+    "runtime"
+    "sync"
+    "sync/atomic"
+
+    "tcc-benchmarks/bench"
+)
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+// multiplicacaoMatrizes implementa bench.Benchmark. Os campos abaixo de
+// configuracao sao o estado de uma execucao, montado por Preparar.
+type multiplicacaoMatrizes struct {
+    configuracao Configuracao
+
+    tamanhoMatriz   int
+    totalThreads    int
+    matrizA         []float64
+    matrizB         []float64
+    matrizResultado []float64
+}
+
+// Novo devolve o benchmark de multiplicacao de matrizes com configuracao,
+// para ser medido com bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &multiplicacaoMatrizes{configuracao: configuracao}
+}
+
+func (k *multiplicacaoMatrizes) Nome() string { return "matmul" }
+
+func (k *multiplicacaoMatrizes) Descricao() string {
+    return "Multiplicacao de matrizes densa em blocos"
+}
+
+func (k *multiplicacaoMatrizes) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Tamanho, "size", "BENCH_SIZE", "dimensao da matriz quadrada"),
+        bench.ParametroInt(&k.configuracao.Threads, "threads", "BENCH_THREADS", "numero de threads"),
+    }
+}
+
+// Preparar aloca as matrizes e preenche A e B com valores pseudoaleatorios.
+func (k *multiplicacaoMatrizes) Preparar(ctx context.Context) error {
+    tamanhoMatriz := max(1, k.configuracao.Tamanho)
+    k.tamanhoMatriz = tamanhoMatriz
+    k.totalThreads = max(1, k.configuracao.Threads)
+    runtime.GOMAXPROCS(k.totalThreads)
+    k.matrizA = make([]float64, tamanhoMatriz*tamanhoMatriz)
+    k.matrizB = make([]float64, tamanhoMatriz*tamanhoMatriz)
+    k.matrizResultado = make([]float64, tamanhoMatriz*tamanhoMatriz)
+    gerador := rand.New(rand.NewSource(42))
+    for indice := range k.matrizA {
+        k.matrizA[indice] = gerador.Float64()
+    }
+    for indice := range k.matrizB {
+        k.matrizB[indice] = gerador.Float64()
+    }
+    return nil
+}
+
+func (k *multiplicacaoMatrizes) Executar(ctx context.Context) (bench.Trabalho, error) {
+    tamanhoMatriz, totalThreads := k.tamanhoMatriz, k.totalThreads
+    matrizA, matrizB, matrizResultado := k.matrizA, k.matrizB, k.matrizResultado
+    var grupo sync.WaitGroup
+    var operacoes int64
+    bloco := 32
+    // calcularBloco acumula em C o bloco (inicioLinha, inicioColuna) inteiro,
+    // percorrendo os blocos de profundidade.
+    calcularBloco := func(inicioLinha, inicioColuna int) {
+        for blocoProfundidade := 0; blocoProfundidade < tamanhoMatriz; blocoProfundidade += bloco {
+            if bench.Interrompido(ctx) {
+                return
+            }
+            maxLinha := min(inicioLinha+bloco, tamanhoMatriz)
+            maxColuna := min(inicioColuna+bloco, tamanhoMatriz)
+            maxProfundidade := min(blocoProfundidade+bloco, tamanhoMatriz)
+            for linha := inicioLinha; linha < maxLinha; linha++ {
+                for profundidade := blocoProfundidade; profundidade < maxProfundidade; profundidade++ {
+                    elementoA := matrizA[linha*tamanhoMatriz+profundidade]
+                    for coluna := inicioColuna; coluna < maxColuna; coluna++ {
+                        matrizResultado[linha*tamanhoMatriz+coluna] += elementoA * matrizB[profundidade*tamanhoMatriz+coluna]
+                    }
+                }
+            }
+            atomic.AddInt64(&operacoes, 2*int64(maxLinha-inicioLinha)*int64(maxColuna-inicioColuna)*int64(maxProfundidade-blocoProfundidade))
+        }
+    }
+    if !bench.VinculoAtivo() {
+        // Uma goroutine por bloco de C, como no kernel original.
+        for blocoLinha := 0; blocoLinha < tamanhoMatriz; blocoLinha += bloco {
+            for blocoColuna := 0; blocoColuna < tamanhoMatriz; blocoColuna += bloco {
+                inicioLinha := blocoLinha
+                inicioColuna := blocoColuna
+                grupo.Add(1)
+                go func() {
+                    defer grupo.Done()
+                    calcularBloco(inicioLinha, inicioColuna)
+                }()
+            }
+        }
+        grupo.Wait()
+        return bench.Trabalho{Tamanho: tamanhoMatriz, Threads: totalThreads, Operacoes: atomic.LoadInt64(&operacoes)}, nil
+    }
+    // Com --bind, totalThreads workers fixos consomem os blocos de um canal;
+    // so eles sao vinculados, um por indice de worker.
+    type tarefa struct {
+        inicioLinha  int
+        inicioColuna int
+    }
+    trabalhos := make(chan tarefa, totalThreads)
+    for worker := 0; worker < totalThreads; worker++ {
+        indiceWorker := worker
+        grupo.Add(1)
+        go func() {
+            defer grupo.Done()
+            defer bench.VincularWorker(indiceWorker, totalThreads)()
+            for job := range trabalhos {
+                calcularBloco(job.inicioLinha, job.inicioColuna)
+            }
+        }()
+    }
+distribuir:
+    for blocoLinha := 0; blocoLinha < tamanhoMatriz; blocoLinha += bloco {
+        for blocoColuna := 0; blocoColuna < tamanhoMatriz; blocoColuna += bloco {
+            if bench.Interrompido(ctx) {
+                break distribuir
+            }
+            trabalhos <- tarefa{inicioLinha: blocoLinha, inicioColuna: blocoColuna}
+        }
+    }
+    close(trabalhos)
+    grupo.Wait()
+    return bench.Trabalho{Tamanho: tamanhoMatriz, Threads: totalThreads, Operacoes: atomic.LoadInt64(&operacoes)}, nil
+}
+
+// Verificar recalcula pelo produto ingenuo a diagonal e os cantos do
+// resultado e os compara com o que o kernel em blocos produziu.
+func (k *multiplicacaoMatrizes) Verificar() error {
+    n := k.tamanhoMatriz
+    conferir := func(linha, coluna int) error {
+        esperado := 0.0
+        for profundidade := 0; profundidade < n; profundidade++ {
+            esperado += k.matrizA[linha*n+profundidade] * k.matrizB[profundidade*n+coluna]
+        }
+        obtido := k.matrizResultado[linha*n+coluna]
+        if math.Abs(obtido-esperado) > 1e-9*math.Max(1, math.Abs(esperado)) {
+            return fmt.Errorf("C[%d][%d] = %g, esperado %g", linha, coluna, obtido, esperado)
+        }
+        return nil
+    }
+    for indice := 0; indice < n; indice++ {
+        if err := conferir(indice, indice); err != nil {
+            return err
+        }
+    }
+    if err := conferir(0, n-1); err != nil {
+        return err
+    }
+    return conferir(n-1, 0)
+}
+
+// toleranciaNorma absorve a diferenca de arredondamento entre o kernel e a
+// referencia quando o compilador funde multiplicacao e soma (FMA).
+const toleranciaNorma = 1e-9
+
+// Checksum e a norma de Frobenius de C.
+func (k *multiplicacaoMatrizes) Checksum() bench.Checksum {
+    somaQuadrados := 0.0
+    for _, valor := range k.matrizResultado {
+        somaQuadrados += valor * valor
+    }
+    return bench.ChecksumReal(math.Sqrt(somaQuadrados), toleranciaNorma)
+}
+
+// ChecksumReferencia calcula a norma de Frobenius do produto ingenuo de tres
+// lacos, sem guardar a matriz.
+func (k *multiplicacaoMatrizes) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    n := k.tamanhoMatriz
+    somaQuadrados := 0.0
+    for linha := 0; linha < n; linha++ {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        for coluna := 0; coluna < n; coluna++ {
+            elemento := 0.0
+            for profundidade := 0; profundidade < n; profundidade++ {
+                elemento += k.matrizA[linha*n+profundidade] * k.matrizB[profundidade*n+coluna]
+            }
+            somaQuadrados += elemento * elemento
+        }
+    }
+    return bench.ChecksumReal(math.Sqrt(somaQuadrados), toleranciaNorma), nil
+}
+
+func (k *multiplicacaoMatrizes) Encerrar() error {
+    k.matrizA, k.matrizB, k.matrizResultado = nil, nil, nil
+    return nil
+}
+
+func min(a, b int) int {
+    if a < b {
+        return a
+    }
+    return b
+}
+
+// Configuracao descreve uma execucao da multiplicacao de matrizes.
+type Configuracao struct {
+    // Tamanho e a dimensao das matrizes quadradas.
+    Tamanho int
+    Threads int
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Tamanho: 1024, Threads: runtime.NumCPU()}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao e ajusta runtime.GOMAXPROCS
+// para configuracao.Threads. Se ctx for cancelado os workers param e o
+// resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
@@ -0,0 +1,71 @@
+package matmul
+
+import (
+    "fmt"
+    "math"
+    "testing"
+
+    "tcc-benchmarks/bench"
+    "tcc-benchmarks/bench/benchtest"
+)
+
+// produtoIngenuo e a multiplicacao de tres lacos usada como referencia.
+func produtoIngenuo(a, b []float64, n int) []float64 {
+    c := make([]float64, n*n)
+    for linha := 0; linha < n; linha++ {
+        for coluna := 0; coluna < n; coluna++ {
+            soma := 0.0
+            for profundidade := 0; profundidade < n; profundidade++ {
+                soma += a[linha*n+profundidade] * b[profundidade*n+coluna]
+            }
+            c[linha*n+coluna] = soma
+        }
+    }
+    return c
+}
+
+func TestMultiplicacaoContraProdutoIngenuo(t *testing.T) {
+    casos := []struct {
+        tamanho int
+        threads int
+        // vinculo "close" exercita os workers fixos usados com --bind.
+        vinculo string
+    }{
+        {tamanho: 1, threads: 1},
+        {tamanho: 31, threads: 1},
+        {tamanho: 32, threads: 2},
+        {tamanho: 33, threads: 3},
+        {tamanho: 70, threads: 4},
+        {tamanho: 100, threads: 7},
+        {tamanho: 70, threads: 3, vinculo: "close"},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("n=%d/threads=%d/bind=%s", caso.tamanho, caso.threads, caso.vinculo), func(t *testing.T) {
+            if caso.vinculo != "" {
+                if err := bench.ConfigurarAfinidade(nil, caso.vinculo); err != nil {
+                    t.Skipf("afinidade indisponivel: %v", err)
+                }
+                t.Cleanup(func() { bench.ConfigurarAfinidade(nil, "none") })
+            }
+            k := Novo(Configuracao{Tamanho: caso.tamanho, Threads: caso.threads}).(*multiplicacaoMatrizes)
+            trabalho := benchtest.Executar(t, k)
+            esperado := produtoIngenuo(k.matrizA, k.matrizB, caso.tamanho)
+            for indice, valor := range k.matrizResultado {
+                if math.Abs(valor-esperado[indice]) > 1e-9*math.Max(1, math.Abs(esperado[indice])) {
+                    t.Fatalf("C[%d][%d] = %g, esperado %g", indice/caso.tamanho, indice%caso.tamanho, valor, esperado[indice])
+                }
+            }
+            if operacoes := 2 * int64(caso.tamanho) * int64(caso.tamanho) * int64(caso.tamanho); trabalho.Operacoes != operacoes {
+                t.Errorf("operacoes = %d, esperado %d", trabalho.Operacoes, operacoes)
+            }
+        })
+    }
+}
+
+func BenchmarkMatmul(b *testing.B) {
+    for _, threads := range []int{1, 2, 4, 8} {
+        b.Run(fmt.Sprintf("n=256/threads=%d", threads), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Tamanho: 256, Threads: threads}))
+        })
+    }
+}
@@ -0,0 +1,166 @@
+package mcpi
+
+import (
+    "context"
+    "fmt"
+    "math/rand"
+    "runtime"
+    "sync"
+
+    "tcc-benchmarks/bench"
+)
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+// monteCarloPi implementa bench.Benchmark. Os campos abaixo de configuracao
+// sao o estado de uma execucao.
+type monteCarloPi struct {
+    configuracao Configuracao
+
+    totalAmostras      int
+    totalThreads       int
+    amostrasPorThread  int
+    pontosDentro       uint64
+    amostrasRealizadas int64
+}
+
+// Novo devolve o benchmark de Monte Carlo para pi com configuracao, para ser
+// medido com bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &monteCarloPi{configuracao: configuracao}
+}
+
+func (k *monteCarloPi) Nome() string { return "mcpi" }
+
+func (k *monteCarloPi) Descricao() string { return "Monte Carlo para pi" }
+
+func (k *monteCarloPi) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Amostras, "size", "BENCH_SIZE", "total de amostras"),
+        bench.ParametroInt(&k.configuracao.Threads, "threads", "BENCH_THREADS", "numero de threads"),
+    }
+}
+
+func (k *monteCarloPi) Preparar(ctx context.Context) error {
+    k.totalAmostras = max(1, k.configuracao.Amostras)
+    k.totalThreads = max(1, k.configuracao.Threads)
+    runtime.GOMAXPROCS(k.totalThreads)
+    k.amostrasPorThread = (k.totalAmostras + k.totalThreads - 1) / k.totalThreads
+    k.pontosDentro, k.amostrasRealizadas = 0, 0
+    return nil
+}
+
+func (k *monteCarloPi) Executar(ctx context.Context) (bench.Trabalho, error) {
+    totalThreads := k.totalThreads
+    amostrasPorThread := k.amostrasPorThread
+    var grupo sync.WaitGroup
+    var mutex sync.Mutex
+    for indiceThread := 0; indiceThread < totalThreads; indiceThread++ {
+        grupo.Add(1)
+        semente := int64(1234 + indiceThread)
+        indiceWorker := indiceThread
+        go func(seed int64) {
+            defer grupo.Done()
+            defer bench.VincularWorker(indiceWorker, totalThreads)()
+            gerador := rand.New(rand.NewSource(seed))
+            pontosInternosLocais := 0
+            amostra := 0
+            for ; amostra < amostrasPorThread; amostra++ {
+                if amostra%4096 == 0 && bench.Interrompido(ctx) {
+                    break
+                }
+                x := gerador.Float64()
+                y := gerador.Float64()
+                if x*x+y*y <= 1.0 {
+                    pontosInternosLocais++
+                }
+            }
+            mutex.Lock()
+            k.pontosDentro += uint64(pontosInternosLocais)
+            k.amostrasRealizadas += int64(amostra)
+            mutex.Unlock()
+        }(semente)
+    }
+    grupo.Wait()
+    return bench.Trabalho{Tamanho: k.totalAmostras, Threads: totalThreads, Operacoes: k.amostrasRealizadas}, nil
+}
+
+// Verificar confere que cada thread sorteou todas as suas amostras e que a
+// fracao dentro do circulo e uma probabilidade.
+func (k *monteCarloPi) Verificar() error {
+    if esperadas := int64(k.amostrasPorThread) * int64(k.totalThreads); k.amostrasRealizadas != esperadas {
+        return fmt.Errorf("amostras realizadas (%d) diferem das planejadas (%d)", k.amostrasRealizadas, esperadas)
+    }
+    if k.pontosDentro > uint64(k.amostrasRealizadas) {
+        return fmt.Errorf("%d pontos dentro do circulo em %d amostras", k.pontosDentro, k.amostrasRealizadas)
+    }
+    return nil
+}
+
+// Checksum e a estimativa de pi, 4 * pontos dentro / amostras.
+func (k *monteCarloPi) Checksum() bench.Checksum {
+    return estimativaPi(k.pontosDentro, k.amostrasRealizadas)
+}
+
+// ChecksumReferencia sorteia em sequencia as amostras de cada thread, com as
+// mesmas sementes.
+func (k *monteCarloPi) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    var pontosDentro uint64
+    for indiceThread := 0; indiceThread < k.totalThreads; indiceThread++ {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        gerador := rand.New(rand.NewSource(int64(1234 + indiceThread)))
+        for amostra := 0; amostra < k.amostrasPorThread; amostra++ {
+            x := gerador.Float64()
+            y := gerador.Float64()
+            if x*x+y*y <= 1.0 {
+                pontosDentro++
+            }
+        }
+    }
+    return estimativaPi(pontosDentro, int64(k.amostrasPorThread)*int64(k.totalThreads)), nil
+}
+
+// estimativaPi usa tolerancia zero: com as mesmas sementes a contagem de
+// pontos e exata.
+func estimativaPi(pontosDentro uint64, amostras int64) bench.Checksum {
+    return bench.ChecksumReal(4*float64(pontosDentro)/float64(amostras), 0)
+}
+
+func (k *monteCarloPi) Encerrar() error {
+    return nil
+}
+
+// Configuracao descreve uma execucao de Monte Carlo para pi.
+type Configuracao struct {
+    Amostras int
+    Threads  int
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Amostras: 1024, Threads: runtime.NumCPU()}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao e ajusta runtime.GOMAXPROCS
+// para configuracao.Threads. Se ctx for cancelado os workers param e o
+// resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
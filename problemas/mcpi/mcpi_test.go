@@ -0,0 +1,43 @@
+package mcpi
+
+import (
+    "fmt"
+    "math"
+    "testing"
+
+    "tcc-benchmarks/bench/benchtest"
+)
+
+func TestEstimativaDePi(t *testing.T) {
+    casos := []struct {
+        amostras   int
+        threads    int
+        tolerancia float64
+    }{
+        {amostras: 100000, threads: 1, tolerancia: 0.05},
+        {amostras: 100000, threads: 3, tolerancia: 0.05},
+        {amostras: 1000000, threads: 4, tolerancia: 0.01},
+        {amostras: 1000003, threads: 7, tolerancia: 0.01},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("amostras=%d/threads=%d", caso.amostras, caso.threads), func(t *testing.T) {
+            k := Novo(Configuracao{Amostras: caso.amostras, Threads: caso.threads}).(*monteCarloPi)
+            trabalho := benchtest.Executar(t, k)
+            if trabalho.Operacoes < int64(caso.amostras) {
+                t.Fatalf("amostras realizadas = %d, esperado ao menos %d", trabalho.Operacoes, caso.amostras)
+            }
+            estimativa := 4 * float64(k.pontosDentro) / float64(trabalho.Operacoes)
+            if math.Abs(estimativa-math.Pi) > caso.tolerancia {
+                t.Errorf("estimativa = %.5f, fora de pi +- %g", estimativa, caso.tolerancia)
+            }
+        })
+    }
+}
+
+func BenchmarkMcpi(b *testing.B) {
+    for _, threads := range []int{1, 2, 4, 8} {
+        b.Run(fmt.Sprintf("amostras=1e7/threads=%d", threads), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Amostras: 10000000, Threads: threads}))
+        })
+    }
+}
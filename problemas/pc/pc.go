@@ -0,0 +1,379 @@
+package pc
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "io"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+
+    "tcc-benchmarks/bench"
+)
+
+// arquivoMarcador identifica os diretorios preenchidos por
+// garantirArquivosAleatorios; o conteudo registra o gerador e o tamanho dos
+// arquivos.
+const arquivoMarcador = ".gerador"
+
+// garantirArquivosAleatorios completa diretorioDestino ate quantidadeArquivos
+// arquivos .bin com o fluxo de math/rand de semente 42: file_N recebe os
+// bytes [N*tamanhoArquivo, (N+1)*tamanhoArquivo) desse fluxo, de modo que um
+// diretorio completado aos poucos fica identico a um gerado de uma vez.
+// Arquivos existentes nunca sao apagados nem reescritos. Se o diretorio ja
+// tem arquivos .bin sem o marcador deste gerador (dados proprios, ou gerados
+// pelas versoes em Java ou Python), eles sao usados como estao e, se
+// faltarem arquivos, devolve erro em vez de misturar geradores.
+func garantirArquivosAleatorios(diretorioDestino string, quantidadeArquivos, tamanhoArquivo int) error {
+    if diretorioDestino == "" {
+        return nil
+    }
+    if _, err := os.Stat(diretorioDestino); os.IsNotExist(err) {
+        if err := os.MkdirAll(diretorioDestino, 0o755); err != nil {
+            return err
+        }
+    }
+    entradas, err := os.ReadDir(diretorioDestino)
+    if err != nil {
+        return err
+    }
+    arquivosExistentes := 0
+    for _, entrada := range entradas {
+        if !entrada.IsDir() && strings.HasSuffix(entrada.Name(), ".bin") {
+            arquivosExistentes++
+        }
+    }
+    if arquivosExistentes >= quantidadeArquivos {
+        return nil
+    }
+    caminhoMarcador := filepath.Join(diretorioDestino, arquivoMarcador)
+    marcador := fmt.Sprintf("math/rand semente=42 tamanho=%d\n", tamanhoArquivo)
+    marcadorAtual, err := os.ReadFile(caminhoMarcador)
+    if err != nil && !os.IsNotExist(err) {
+        return err
+    }
+    if arquivosExistentes > 0 && string(marcadorAtual) != marcador {
+        return fmt.Errorf("%d arquivos .bin de outro gerador em %s, %d necessarios; use outro diretorio", arquivosExistentes, diretorioDestino, quantidadeArquivos)
+    }
+    if arquivosExistentes == 0 {
+        if err := os.WriteFile(caminhoMarcador, []byte(marcador), 0o644); err != nil {
+            return err
+        }
+    }
+    buffer := make([]byte, tamanhoArquivo)
+    gerador := rand.New(rand.NewSource(42))
+    for indice := 0; indice < quantidadeArquivos; indice++ {
+        if _, err := gerador.Read(buffer); err != nil {
+            return err
+        }
+        if indice < arquivosExistentes {
+            continue
+        }
+        // Grava em um temporario sem a extensao .bin: uma geracao interrompida
+        // nao deixa arquivo truncado contando como existente.
+        caminho := filepath.Join(diretorioDestino, fmt.Sprintf("file_%06d.bin", indice))
+        if err := os.WriteFile(caminho+".tmp", buffer, 0o644); err != nil {
+            return err
+        }
+        if err := os.Rename(caminho+".tmp", caminho); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+// produtorConsumidor implementa bench.Benchmark. Os campos abaixo de
+// configuracao sao o estado de uma execucao, montado por Preparar.
+type produtorConsumidor struct {
+    configuracao Configuracao
+
+    totalArquivos    int
+    totalThreads     int
+    caminhosArquivos []string
+    filaTarefas      chan string
+    totalProduzido   int64
+    totalConsumido   int64
+    totalFalhas      int64
+    somaHashes       uint64
+    startSignal      chan struct{}
+    iniciado         bool
+    cancelar         context.CancelFunc
+    produtoresWG     sync.WaitGroup
+    consumidoresWG   sync.WaitGroup
+}
+
+// Novo devolve o benchmark de produtor-consumidor com configuracao, para ser
+// medido com bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &produtorConsumidor{configuracao: configuracao}
+}
+
+func (k *produtorConsumidor) Nome() string { return "pc" }
+
+func (k *produtorConsumidor) Descricao() string {
+    return "Produtor-Consumidor (buffer limitado, SHA-256 em arquivos)"
+}
+
+func (k *produtorConsumidor) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Tamanho, "size", "BENCH_SIZE", "tamanho/escala do benchmark"),
+        bench.ParametroInt(&k.configuracao.Threads, "threads", "BENCH_THREADS", "numero de threads/gorrotinas"),
+        bench.ParametroTexto(&k.configuracao.Diretorio, "dir", "BENCH_DIR", "diretorio de arquivos (padrao: data do projeto)"),
+        bench.ParametroInt(&k.configuracao.Buffer, "buffer", "BENCH_BUFFER", "capacidade do buffer"),
+    }
+}
+
+// Preparar gera os arquivos que faltarem e cria produtores e consumidores,
+// que ficam parados em startSignal.
+func (k *produtorConsumidor) Preparar(ctx context.Context) error {
+    runtime.GOMAXPROCS(max(1, k.configuracao.Threads))
+    totalArquivos := k.configuracao.Tamanho
+    totalThreads := max(2, k.configuracao.Threads)
+    capacidadeBuffer := max(1, k.configuracao.Buffer)
+    diretorioDados := k.configuracao.Diretorio
+    if diretorioDados == "" {
+        diretorioDados = defaultDataDir
+    }
+    if err := garantirArquivosAleatorios(diretorioDados, totalArquivos, 64*1024); err != nil {
+        return bench.NovoErro(bench.ErroPreparacao, fmt.Errorf("nao foi possivel gerar dados: %w", err), map[string]string{"diretorio": diretorioDados})
+    }
+    caminhosArquivos := make([]string, 0, totalArquivos)
+    _ = filepath.WalkDir(diretorioDados, func(caminho string, entrada os.DirEntry, err error) error {
+        if err == nil && !entrada.IsDir() && strings.HasSuffix(entrada.Name(), ".bin") {
+            caminhosArquivos = append(caminhosArquivos, caminho)
+        }
+        return nil
+    })
+    if len(caminhosArquivos) == 0 {
+        return bench.NovoErro(bench.ErroPreparacao, errors.New("nenhum arquivo encontrado"), map[string]string{"diretorio": diretorioDados})
+    }
+    if totalArquivos < len(caminhosArquivos) {
+        caminhosArquivos = caminhosArquivos[:totalArquivos]
+    }
+    produtores := totalThreads / 2
+    if produtores < 1 {
+        produtores = 1
+    }
+    consumidores := totalThreads - produtores
+    if consumidores < 1 {
+        consumidores = 1
+        produtores = max(1, totalThreads-consumidores)
+    }
+
+    k.totalArquivos, k.totalThreads = totalArquivos, totalThreads
+    k.caminhosArquivos = caminhosArquivos
+    k.filaTarefas = make(chan string, capacidadeBuffer)
+    k.totalProduzido, k.totalConsumido, k.totalFalhas, k.somaHashes = 0, 0, 0, 0
+    k.startSignal = make(chan struct{})
+    k.iniciado = false
+    ctx, k.cancelar = context.WithCancel(ctx)
+    filaTarefas := k.filaTarefas
+    startSignal := k.startSignal
+
+    arquivosPorProdutor := (len(caminhosArquivos) + produtores - 1) / produtores
+    for indiceProdutor := 0; indiceProdutor < produtores; indiceProdutor++ {
+        inicio := indiceProdutor * arquivosPorProdutor
+        fim := inicio + arquivosPorProdutor
+        if inicio >= len(caminhosArquivos) {
+            break
+        }
+        if fim > len(caminhosArquivos) {
+            fim = len(caminhosArquivos)
+        }
+        lote := append([]string(nil), caminhosArquivos[inicio:fim]...)
+        indiceWorker := indiceProdutor
+        k.produtoresWG.Add(1)
+        go func() {
+            defer k.produtoresWG.Done()
+            defer bench.VincularWorker(indiceWorker, produtores+consumidores)()
+            <-startSignal
+            for _, caminho := range lote {
+                select {
+                case filaTarefas <- caminho:
+                    atomic.AddInt64(&k.totalProduzido, 1)
+                case <-ctx.Done():
+                    return
+                }
+            }
+        }()
+    }
+
+    for indiceConsumidor := 0; indiceConsumidor < consumidores; indiceConsumidor++ {
+        indiceWorker := produtores + indiceConsumidor
+        k.consumidoresWG.Add(1)
+        go func() {
+            defer k.consumidoresWG.Done()
+            defer bench.VincularWorker(indiceWorker, produtores+consumidores)()
+            bufferLeitura := make([]byte, 1<<20)
+            <-startSignal
+            for caminhoArquivo := range filaTarefas {
+                if bench.Interrompido(ctx) {
+                    return
+                }
+                arquivo, err := os.Open(caminhoArquivo)
+                if err != nil {
+                    bench.ContarErro("abrir_arquivo")
+                    atomic.AddInt64(&k.totalFalhas, 1)
+                    continue
+                }
+                hashArquivo := sha256.New()
+                var errLeitura error
+                for {
+                    bytesLidos, er := arquivo.Read(bufferLeitura)
+                    if bytesLidos > 0 {
+                        hashArquivo.Write(bufferLeitura[:bytesLidos])
+                    }
+                    if er != nil {
+                        if er != io.EOF {
+                            errLeitura = er
+                        }
+                        break
+                    }
+                }
+                arquivo.Close()
+                if errLeitura != nil {
+                    // O hash de uma leitura truncada mudaria o checksum sem
+                    // aviso; o arquivo conta como falha, como na abertura.
+                    bench.ContarErro("ler_arquivo")
+                    atomic.AddInt64(&k.totalFalhas, 1)
+                    continue
+                }
+                resumo := hashArquivo.Sum(nil)
+                if len(resumo) >= 8 {
+                    atomic.AddUint64(&k.somaHashes, binary.LittleEndian.Uint64(resumo[:8]))
+                }
+                atomic.AddInt64(&k.totalConsumido, 1)
+            }
+        }()
+    }
+
+    bench.RegistrarFila(func() int { return len(filaTarefas) })
+    return nil
+}
+
+func (k *produtorConsumidor) Executar(ctx context.Context) (bench.Trabalho, error) {
+    k.iniciado = true
+    close(k.startSignal)
+    go func() {
+        k.produtoresWG.Wait()
+        close(k.filaTarefas)
+    }()
+    k.consumidoresWG.Wait()
+    return bench.Trabalho{Tamanho: k.totalArquivos, Threads: k.totalThreads, Itens: atomic.LoadInt64(&k.totalConsumido)}, nil
+}
+
+// Verificar confere que todo item produzido foi consumido ou contado como
+// falha.
+func (k *produtorConsumidor) Verificar() error {
+    produzidos := atomic.LoadInt64(&k.totalProduzido)
+    consumidos := atomic.LoadInt64(&k.totalConsumido)
+    falhas := atomic.LoadInt64(&k.totalFalhas)
+    if consumidos+falhas != produzidos {
+        return bench.NovoErro(bench.ErroKernel, errors.New("itens produzidos e consumidos nao conferem"), map[string]string{
+            "produzidos": strconv.FormatInt(produzidos, 10),
+            "consumidos": strconv.FormatInt(consumidos, 10),
+            "falhas":     strconv.FormatInt(falhas, 10),
+        })
+    }
+    return nil
+}
+
+// Checksum e a soma, modulo 2^64, dos 8 primeiros bytes (little-endian) do
+// SHA-256 de cada arquivo processado.
+func (k *produtorConsumidor) Checksum() bench.Checksum {
+    return bench.ChecksumInteiro(atomic.LoadUint64(&k.somaHashes))
+}
+
+// ChecksumReferencia hasheia os mesmos arquivos em sequencia.
+func (k *produtorConsumidor) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    var soma uint64
+    for _, caminho := range k.caminhosArquivos {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        dados, err := os.ReadFile(caminho)
+        if err != nil {
+            return bench.Checksum{}, err
+        }
+        resumo := sha256.Sum256(dados)
+        soma += binary.LittleEndian.Uint64(resumo[:8])
+    }
+    return bench.ChecksumInteiro(soma), nil
+}
+
+// Encerrar libera produtores e consumidores que nao chegaram a comecar.
+func (k *produtorConsumidor) Encerrar() error {
+    if k.cancelar != nil {
+        k.cancelar()
+    }
+    if k.startSignal != nil && !k.iniciado {
+        close(k.startSignal)
+        k.produtoresWG.Wait()
+        close(k.filaTarefas)
+    }
+    k.consumidoresWG.Wait()
+    k.filaTarefas, k.startSignal, k.cancelar, k.caminhosArquivos = nil, nil, nil, nil
+    return nil
+}
+
+var defaultDataDir string
+
+func init() {
+    _, arquivo, _, ok := runtime.Caller(0)
+    if ok {
+        defaultDataDir = filepath.Join(filepath.Dir(arquivo), "..", "..", "concorrencia", "dados_pc")
+    } else {
+        defaultDataDir = "concorrencia/dados_pc"
+    }
+}
+
+// Configuracao descreve uma execucao de produtor-consumidor.
+type Configuracao struct {
+    // Tamanho e o numero de arquivos de 64 KiB processados.
+    Tamanho int
+    // Threads e dividido ao meio entre produtores e consumidores (minimo 2).
+    Threads int
+    Buffer  int
+    // Diretorio guarda os arquivos .bin; os que faltarem sao gerados antes da
+    // regiao medida. Vazio usa concorrencia/dados_pc do projeto.
+    Diretorio string
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Tamanho: 1000, Threads: runtime.NumCPU(), Buffer: 256, Diretorio: defaultDataDir}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao: gera os arquivos que faltarem
+// e ajusta runtime.GOMAXPROCS para configuracao.Threads. Falhas ao gerar os
+// dados sao erros de preparacao; uma contagem inconsistente de itens ao fim
+// e erro de kernel e vem acompanhada das metricas coletadas. Se ctx for
+// cancelado os workers param e o resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
@@ -0,0 +1,151 @@
+package pc
+
+import (
+    "bytes"
+    "crypto/sha256"
+    "encoding/binary"
+    "fmt"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+
+    "tcc-benchmarks/bench/benchtest"
+)
+
+// somaEsperada calcula, sem concorrencia, a soma dos 8 primeiros bytes do
+// SHA-256 dos primeiros quantidade arquivos .bin de diretorio, na ordem em que
+// o kernel os distribui.
+func somaEsperada(t *testing.T, diretorio string, quantidade int) uint64 {
+    t.Helper()
+    entradas, err := os.ReadDir(diretorio)
+    if err != nil {
+        t.Fatal(err)
+    }
+    var nomes []string
+    for _, entrada := range entradas {
+        if strings.HasSuffix(entrada.Name(), ".bin") {
+            nomes = append(nomes, entrada.Name())
+        }
+    }
+    sort.Strings(nomes)
+    if len(nomes) < quantidade {
+        t.Fatalf("%d arquivos .bin em %s, esperado ao menos %d", len(nomes), diretorio, quantidade)
+    }
+    var soma uint64
+    for _, nome := range nomes[:quantidade] {
+        dados, err := os.ReadFile(filepath.Join(diretorio, nome))
+        if err != nil {
+            t.Fatal(err)
+        }
+        resumo := sha256.Sum256(dados)
+        soma += binary.LittleEndian.Uint64(resumo[:8])
+    }
+    return soma
+}
+
+func TestCadaArquivoHashUmaVez(t *testing.T) {
+    casos := []struct {
+        arquivos   int
+        existentes int
+        threads    int
+        buffer     int
+    }{
+        {arquivos: 1, threads: 2, buffer: 1},
+        {arquivos: 10, threads: 2, buffer: 1},
+        {arquivos: 37, threads: 4, buffer: 3},
+        {arquivos: 64, threads: 7, buffer: 256},
+        {arquivos: 5, existentes: 20, threads: 3, buffer: 2},
+        {arquivos: 12, existentes: 4, threads: 1, buffer: 8},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("arquivos=%d/existentes=%d/threads=%d/buffer=%d", caso.arquivos, caso.existentes, caso.threads, caso.buffer), func(t *testing.T) {
+            diretorio := t.TempDir()
+            if caso.existentes > 0 {
+                if err := garantirArquivosAleatorios(diretorio, caso.existentes, 64*1024); err != nil {
+                    t.Fatal(err)
+                }
+            }
+            if err := os.WriteFile(filepath.Join(diretorio, "LEIAME.txt"), []byte("ignorado"), 0o644); err != nil {
+                t.Fatal(err)
+            }
+            k := Novo(Configuracao{Tamanho: caso.arquivos, Threads: caso.threads, Buffer: caso.buffer, Diretorio: diretorio}).(*produtorConsumidor)
+            trabalho := benchtest.Executar(t, k)
+            if trabalho.Itens != int64(caso.arquivos) {
+                t.Errorf("itens consumidos = %d, esperado %d", trabalho.Itens, caso.arquivos)
+            }
+            if k.totalProduzido != int64(caso.arquivos) || k.totalFalhas != 0 {
+                t.Errorf("produzidos = %d, falhas = %d; esperado %d e 0", k.totalProduzido, k.totalFalhas, caso.arquivos)
+            }
+            if esperada := somaEsperada(t, diretorio, caso.arquivos); k.somaHashes != esperada {
+                t.Errorf("soma dos hashes = %d, esperado %d", k.somaHashes, esperada)
+            }
+        })
+    }
+}
+
+func TestGeracaoSegueFluxoUnico(t *testing.T) {
+    deUmaVez := t.TempDir()
+    if err := garantirArquivosAleatorios(deUmaVez, 6, 1024); err != nil {
+        t.Fatal(err)
+    }
+    aosPoucos := t.TempDir()
+    for _, quantidade := range []int{2, 3, 6} {
+        if err := garantirArquivosAleatorios(aosPoucos, quantidade, 1024); err != nil {
+            t.Fatal(err)
+        }
+    }
+    fluxo := make([]byte, 6*1024)
+    rand.New(rand.NewSource(42)).Read(fluxo)
+    for indice := 0; indice < 6; indice++ {
+        nome := fmt.Sprintf("file_%06d.bin", indice)
+        for _, diretorio := range []string{deUmaVez, aosPoucos} {
+            dados, err := os.ReadFile(filepath.Join(diretorio, nome))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if !bytes.Equal(dados, fluxo[indice*1024:(indice+1)*1024]) {
+                t.Errorf("%s em %s difere do fluxo de semente 42", nome, diretorio)
+            }
+        }
+    }
+}
+
+func TestPreservaArquivosDeOutroGerador(t *testing.T) {
+    diretorio := t.TempDir()
+    for indice := 0; indice < 3; indice++ {
+        caminho := filepath.Join(diretorio, fmt.Sprintf("file_%06d.bin", indice))
+        if err := os.WriteFile(caminho, []byte("outro gerador"), 0o644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := garantirArquivosAleatorios(diretorio, 3, 1024); err != nil {
+        t.Fatalf("arquivos suficientes de outro gerador: %v", err)
+    }
+    if err := garantirArquivosAleatorios(diretorio, 5, 1024); err == nil {
+        t.Error("completar arquivos de outro gerador deveria falhar")
+    }
+    entradas, err := os.ReadDir(diretorio)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(entradas) != 3 {
+        t.Errorf("%d entradas no diretorio, esperado as 3 originais", len(entradas))
+    }
+    for _, entrada := range entradas {
+        if dados, err := os.ReadFile(filepath.Join(diretorio, entrada.Name())); err != nil || string(dados) != "outro gerador" {
+            t.Errorf("%s foi alterado", entrada.Name())
+        }
+    }
+}
+
+func BenchmarkPc(b *testing.B) {
+    diretorio := b.TempDir()
+    for _, threads := range []int{2, 4, 8} {
+        b.Run(fmt.Sprintf("arquivos=200/threads=%d", threads), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Tamanho: 200, Threads: threads, Buffer: 256, Diretorio: diretorio}))
+        })
+    }
+}
@@ -0,0 +1,195 @@
+package phil
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+    "fmt"
+    "math/rand"
+    "runtime"
+    "sync"
+    "sync/atomic"
+
+    "tcc-benchmarks/bench"
+)
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+// jantarFilosofos implementa bench.Benchmark. Os campos abaixo de
+// configuracao sao o estado de uma execucao, montado por Preparar.
+type jantarFilosofos struct {
+    configuracao Configuracao
+
+    totalRodadas        int
+    totalFilosofos      int
+    acumuladorApetite   uint64
+    iteracoesRealizadas int64
+    startSignal         chan struct{}
+    iniciado            bool
+    cancelar            context.CancelFunc
+    wg                  sync.WaitGroup
+}
+
+// Novo devolve o benchmark do jantar dos filosofos com configuracao, para ser
+// medido com bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &jantarFilosofos{configuracao: configuracao}
+}
+
+func (k *jantarFilosofos) Nome() string { return "phil" }
+
+func (k *jantarFilosofos) Descricao() string { return "Jantar dos Filosofos (deadlock-free)" }
+
+func (k *jantarFilosofos) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Rodadas, "size", "BENCH_SIZE", "numero de rodadas de pensamento/refeicao"),
+        bench.ParametroInt(&k.configuracao.Filosofos, "threads", "BENCH_THREADS", "numero de filosofos"),
+    }
+}
+
+// Preparar cria os garfos e os filosofos, que ficam parados em startSignal.
+func (k *jantarFilosofos) Preparar(ctx context.Context) error {
+    runtime.GOMAXPROCS(max(1, k.configuracao.Filosofos))
+    totalFilosofos := max(2, k.configuracao.Filosofos)
+    totalRodadas := max(1, k.configuracao.Rodadas)
+    k.totalFilosofos, k.totalRodadas = totalFilosofos, totalRodadas
+    k.acumuladorApetite, k.iteracoesRealizadas = 0, 0
+    k.startSignal = make(chan struct{})
+    k.iniciado = false
+    ctx, k.cancelar = context.WithCancel(ctx)
+    startSignal := k.startSignal
+    garfosDisponiveis := make([]sync.Mutex, totalFilosofos)
+    for indiceFilosofo := 0; indiceFilosofo < totalFilosofos; indiceFilosofo++ {
+        k.wg.Add(1)
+        filosofoID := indiceFilosofo
+        go func() {
+            defer k.wg.Done()
+            defer bench.VincularWorker(filosofoID, totalFilosofos)()
+            <-startSignal
+            garfoEsquerdo := filosofoID
+            garfoDireito := (filosofoID + 1) % totalFilosofos
+            gerador := rand.New(rand.NewSource(int64(2024 + filosofoID)))
+            rodada := 0
+            for ; rodada < totalRodadas; rodada++ {
+                if bench.Interrompido(ctx) {
+                    break
+                }
+                somatorioLocal := pensar(gerador, filosofoID, rodada)
+                if filosofoID%2 == 0 {
+                    garfosDisponiveis[garfoEsquerdo].Lock()
+                    garfosDisponiveis[garfoDireito].Lock()
+                } else {
+                    garfosDisponiveis[garfoDireito].Lock()
+                    garfosDisponiveis[garfoEsquerdo].Lock()
+                }
+                atomic.AddUint64(&k.acumuladorApetite, comer(filosofoID, rodada)+somatorioLocal)
+                garfosDisponiveis[garfoEsquerdo].Unlock()
+                garfosDisponiveis[garfoDireito].Unlock()
+            }
+            atomic.AddInt64(&k.iteracoesRealizadas, int64(rodada))
+        }()
+    }
+    return nil
+}
+
+// pensar e o trabalho feito sem garfos numa rodada.
+func pensar(gerador *rand.Rand, filosofoID, rodada int) uint64 {
+    ciclosPensando := gerador.Intn(400) + 200
+    somatorioLocal := uint64(0)
+    for iteracao := 0; iteracao < ciclosPensando; iteracao++ {
+        somatorioLocal += uint64((iteracao + filosofoID + rodada) % 97)
+    }
+    return somatorioLocal
+}
+
+// comer e o trabalho feito com os dois garfos numa rodada.
+func comer(filosofoID, rodada int) uint64 {
+    dadosHash := make([]byte, 16)
+    binary.LittleEndian.PutUint64(dadosHash[:8], uint64(filosofoID))
+    binary.LittleEndian.PutUint64(dadosHash[8:], uint64(rodada))
+    hashRodada := sha256.Sum256(dadosHash)
+    return binary.LittleEndian.Uint64(hashRodada[:8])
+}
+
+func (k *jantarFilosofos) Executar(ctx context.Context) (bench.Trabalho, error) {
+    k.iniciado = true
+    close(k.startSignal)
+    k.wg.Wait()
+    return bench.Trabalho{Tamanho: k.totalRodadas, Threads: k.totalFilosofos, Iteracoes: atomic.LoadInt64(&k.iteracoesRealizadas)}, nil
+}
+
+// Verificar confere que todos os filosofos comeram todas as rodadas.
+func (k *jantarFilosofos) Verificar() error {
+    if realizadas, esperadas := atomic.LoadInt64(&k.iteracoesRealizadas), int64(k.totalRodadas)*int64(k.totalFilosofos); realizadas != esperadas {
+        return fmt.Errorf("refeicoes realizadas (%d) diferem das planejadas (%d)", realizadas, esperadas)
+    }
+    return nil
+}
+
+// Checksum e o acumulador de todas as rodadas; a soma modulo 2^64 nao
+// depende da ordem em que os filosofos comeram.
+func (k *jantarFilosofos) Checksum() bench.Checksum {
+    return bench.ChecksumInteiro(atomic.LoadUint64(&k.acumuladorApetite))
+}
+
+// ChecksumReferencia faz as rodadas de cada filosofo em sequencia, sem
+// garfos.
+func (k *jantarFilosofos) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    var acumulador uint64
+    for filosofoID := 0; filosofoID < k.totalFilosofos; filosofoID++ {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        gerador := rand.New(rand.NewSource(int64(2024 + filosofoID)))
+        for rodada := 0; rodada < k.totalRodadas; rodada++ {
+            acumulador += pensar(gerador, filosofoID, rodada) + comer(filosofoID, rodada)
+        }
+    }
+    return bench.ChecksumInteiro(acumulador), nil
+}
+
+// Encerrar libera filosofos que nao chegaram a comecar.
+func (k *jantarFilosofos) Encerrar() error {
+    if k.cancelar != nil {
+        k.cancelar()
+    }
+    if k.startSignal != nil && !k.iniciado {
+        close(k.startSignal)
+    }
+    k.wg.Wait()
+    k.startSignal, k.cancelar = nil, nil
+    return nil
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
+
+// Configuracao descreve uma execucao do jantar dos filosofos.
+type Configuracao struct {
+    Rodadas   int
+    Filosofos int
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Rodadas: 1000, Filosofos: runtime.NumCPU()}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao e ajusta runtime.GOMAXPROCS
+// para configuracao.Filosofos. Se ctx for cancelado cada filosofo termina a
+// rodada corrente e o resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
@@ -0,0 +1,77 @@
+package phil
+
+import (
+    "context"
+    "fmt"
+    "testing"
+    "time"
+
+    "tcc-benchmarks/bench/benchtest"
+)
+
+// TestSemDeadlock deve ser rodado tambem com go test -race: alem de terminar
+// dentro do prazo, cada filosofo precisa comer todas as rodadas. As fases sao
+// chamadas aqui, e nao por benchtest.Executar, para que so Executar rode na
+// gorrotina vigiada.
+func TestSemDeadlock(t *testing.T) {
+    casos := []struct {
+        filosofos int
+        rodadas   int
+    }{
+        {filosofos: 1, rodadas: 10},
+        {filosofos: 2, rodadas: 1},
+        {filosofos: 2, rodadas: 500},
+        {filosofos: 3, rodadas: 200},
+        {filosofos: 5, rodadas: 200},
+        {filosofos: 16, rodadas: 100},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("filosofos=%d/rodadas=%d", caso.filosofos, caso.rodadas), func(t *testing.T) {
+            k := Novo(Configuracao{Rodadas: caso.rodadas, Filosofos: caso.filosofos}).(*jantarFilosofos)
+            ctx := context.Background()
+            if err := k.Preparar(ctx); err != nil {
+                t.Fatal(err)
+            }
+            concluido := make(chan error, 1)
+            go func() {
+                _, err := k.Executar(ctx)
+                concluido <- err
+            }()
+            select {
+            case err := <-concluido:
+                if err != nil {
+                    t.Fatal(err)
+                }
+            case <-time.After(30 * time.Second):
+                // Encerrar esperaria os filosofos presos; o panic mostra
+                // onde cada gorrotina parou.
+                panic("os filosofos nao terminaram em 30s (deadlock?)")
+            }
+            if err := k.Verificar(); err != nil {
+                t.Error(err)
+            }
+            referencia, err := k.ChecksumReferencia(ctx)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if obtido := k.Checksum(); !obtido.Confere(referencia) {
+                t.Errorf("checksum %s, referencia %s", obtido.Valor, referencia.Valor)
+            }
+            if err := k.Encerrar(); err != nil {
+                t.Error(err)
+            }
+            filosofos := max(2, caso.filosofos)
+            if esperadas := int64(filosofos) * int64(caso.rodadas); k.iteracoesRealizadas != esperadas {
+                t.Errorf("refeicoes = %d, esperado %d", k.iteracoesRealizadas, esperadas)
+            }
+        })
+    }
+}
+
+func BenchmarkPhil(b *testing.B) {
+    for _, filosofos := range []int{2, 4, 8} {
+        b.Run(fmt.Sprintf("rodadas=1000/filosofos=%d", filosofos), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Rodadas: 1000, Filosofos: filosofos}))
+        })
+    }
+}
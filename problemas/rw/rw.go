@@ -0,0 +1,232 @@
+package rw
+
+import (
+    "context"
+    "fmt"
+    "math/rand"
+    "runtime"
+    "sync"
+    "sync/atomic"
+
+    "tcc-benchmarks/bench"
+)
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+type mapaProtegido struct {
+    dados         map[uint64]uint64
+    sincronizador sync.RWMutex
+}
+
+// leitoresEscritores implementa bench.Benchmark. Os campos abaixo de
+// configuracao sao o estado de uma execucao, montado por Preparar.
+type leitoresEscritores struct {
+    configuracao Configuracao
+
+    tamanhoChaves       int
+    totalThreads        int
+    totalOperacoes      int
+    percentualLeituras  int
+    armazenamento       *mapaProtegido
+    startSignal         chan struct{}
+    iniciado            bool
+    cancelar            context.CancelFunc
+    wg                  sync.WaitGroup
+    operacoesExecutadas int64
+}
+
+// Novo devolve o benchmark de leitores-escritores com configuracao, para ser
+// medido com bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &leitoresEscritores{configuracao: configuracao}
+}
+
+func (k *leitoresEscritores) Nome() string { return "rw" }
+
+func (k *leitoresEscritores) Descricao() string { return "Leitores-Escritores com RWMutex" }
+
+func (k *leitoresEscritores) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Tamanho, "size", "BENCH_SIZE", "tamanho da chave base"),
+        bench.ParametroInt(&k.configuracao.Threads, "threads", "BENCH_THREADS", "numero de threads"),
+        bench.ParametroInt(&k.configuracao.PercentualLeitura, "read_pct", "BENCH_READ_PCT", "percentual de leituras"),
+    }
+}
+
+// Preparar cria o mapa e os workers, que ficam parados em startSignal.
+func (k *leitoresEscritores) Preparar(ctx context.Context) error {
+    runtime.GOMAXPROCS(max(1, k.configuracao.Threads))
+    tamanhoChaves := k.configuracao.Tamanho
+    totalThreads := max(1, k.configuracao.Threads)
+    percentualLeituras := k.configuracao.PercentualLeitura
+    if percentualLeituras < 0 {
+        percentualLeituras = 0
+    }
+    if percentualLeituras > 100 {
+        percentualLeituras = 100
+    }
+    totalOperacoes := tamanhoChaves * 1000
+
+    k.tamanhoChaves, k.totalThreads, k.totalOperacoes = tamanhoChaves, totalThreads, totalOperacoes
+    k.percentualLeituras = percentualLeituras
+    k.armazenamento = &mapaProtegido{dados: make(map[uint64]uint64, 1024)}
+    k.startSignal = make(chan struct{})
+    k.iniciado = false
+    k.operacoesExecutadas = 0
+    ctx, k.cancelar = context.WithCancel(ctx)
+    armazenamento := k.armazenamento
+    startSignal := k.startSignal
+    baseOperacoes := totalOperacoes / totalThreads
+    restoOperacoes := totalOperacoes % totalThreads
+
+    for indice := 0; indice < totalThreads; indice++ {
+        quantidadeOperacoes := baseOperacoes
+        if indice < restoOperacoes {
+            quantidadeOperacoes++
+        }
+        if quantidadeOperacoes == 0 {
+            continue
+        }
+        k.wg.Add(1)
+        semente := int64(1234 + indice)
+        indiceWorker := indice
+        go func(seed int64, totalOperacoesThread int) {
+            defer k.wg.Done()
+            defer bench.VincularWorker(indiceWorker, totalThreads)()
+            gerador := rand.New(rand.NewSource(seed))
+            <-startSignal
+            localExecutadas := 0
+            for operacao := 0; operacao < totalOperacoesThread; operacao++ {
+                if operacao%1024 == 0 && bench.Interrompido(ctx) {
+                    break
+                }
+                localExecutadas++
+                identificador := uint64(gerador.Int63n(int64(tamanhoChaves*10 + 1)))
+                if gerador.Intn(100) < percentualLeituras {
+                    armazenamento.sincronizador.RLock()
+                    _ = armazenamento.dados[identificador]
+                    armazenamento.sincronizador.RUnlock()
+                    continue
+                }
+                novoValor := uint64(gerador.Int63())
+                armazenamento.sincronizador.Lock()
+                armazenamento.dados[identificador] = novoValor
+                armazenamento.sincronizador.Unlock()
+            }
+            atomic.AddInt64(&k.operacoesExecutadas, int64(localExecutadas))
+        }(semente, quantidadeOperacoes)
+    }
+    return nil
+}
+
+func (k *leitoresEscritores) Executar(ctx context.Context) (bench.Trabalho, error) {
+    k.iniciado = true
+    close(k.startSignal)
+    k.wg.Wait()
+    return bench.Trabalho{Tamanho: k.tamanhoChaves, Threads: k.totalThreads, Operacoes: atomic.LoadInt64(&k.operacoesExecutadas)}, nil
+}
+
+// Verificar confere que todas as operacoes foram feitas e que o mapa so tem
+// chaves do intervalo sorteado.
+func (k *leitoresEscritores) Verificar() error {
+    if executadas := atomic.LoadInt64(&k.operacoesExecutadas); executadas != int64(k.totalOperacoes) {
+        return fmt.Errorf("operacoes executadas (%d) diferem das planejadas (%d)", executadas, k.totalOperacoes)
+    }
+    if chaves := len(k.armazenamento.dados); chaves > k.tamanhoChaves*10+1 {
+        return fmt.Errorf("mapa com %d chaves, acima do limite de %d", chaves, k.tamanhoChaves*10+1)
+    }
+    return nil
+}
+
+// Checksum resume o mapa final pelas chaves presentes: quantidade e soma.
+// Os valores ficam de fora porque dependem de qual escritor chegou por ultimo.
+func (k *leitoresEscritores) Checksum() bench.Checksum {
+    var somaChaves uint64
+    for chave := range k.armazenamento.dados {
+        somaChaves += chave
+    }
+    return checksumChaves(len(k.armazenamento.dados), somaChaves)
+}
+
+// ChecksumReferencia repete, sem concorrencia e sem o mapa compartilhado, a
+// sequencia sorteada por cada worker e anota as chaves escritas.
+func (k *leitoresEscritores) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    escritas := map[uint64]struct{}{}
+    baseOperacoes := k.totalOperacoes / k.totalThreads
+    restoOperacoes := k.totalOperacoes % k.totalThreads
+    for indice := 0; indice < k.totalThreads; indice++ {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        quantidadeOperacoes := baseOperacoes
+        if indice < restoOperacoes {
+            quantidadeOperacoes++
+        }
+        gerador := rand.New(rand.NewSource(int64(1234 + indice)))
+        for operacao := 0; operacao < quantidadeOperacoes; operacao++ {
+            identificador := uint64(gerador.Int63n(int64(k.tamanhoChaves*10 + 1)))
+            if gerador.Intn(100) < k.percentualLeituras {
+                continue
+            }
+            gerador.Int63()
+            escritas[identificador] = struct{}{}
+        }
+    }
+    var somaChaves uint64
+    for chave := range escritas {
+        somaChaves += chave
+    }
+    return checksumChaves(len(escritas), somaChaves), nil
+}
+
+func checksumChaves(quantidade int, soma uint64) bench.Checksum {
+    return bench.Checksum{Valor: fmt.Sprintf("%d:%d", quantidade, soma)}
+}
+
+// Encerrar libera workers que nao chegaram a comecar e descarta o mapa.
+func (k *leitoresEscritores) Encerrar() error {
+    if k.cancelar != nil {
+        k.cancelar()
+    }
+    if k.startSignal != nil && !k.iniciado {
+        close(k.startSignal)
+    }
+    k.wg.Wait()
+    k.armazenamento, k.startSignal, k.cancelar = nil, nil, nil
+    return nil
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
+
+// Configuracao descreve uma execucao de leitores-escritores.
+type Configuracao struct {
+    // Tamanho e a escala: Tamanho*1000 operacoes sobre Tamanho*10+1 chaves.
+    Tamanho           int
+    Threads           int
+    PercentualLeitura int
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Tamanho: 1000, Threads: runtime.NumCPU(), PercentualLeitura: 80}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao e ajusta runtime.GOMAXPROCS
+// para configuracao.Threads. Se ctx for cancelado os workers param e o
+// resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
@@ -0,0 +1,48 @@
+package rw
+
+import (
+    "fmt"
+    "testing"
+
+    "tcc-benchmarks/bench/benchtest"
+)
+
+func TestContagemDeOperacoes(t *testing.T) {
+    casos := []struct {
+        tamanho           int
+        threads           int
+        percentualLeitura int
+    }{
+        {tamanho: 1, threads: 1, percentualLeitura: 80},
+        {tamanho: 3, threads: 7, percentualLeitura: 80},
+        {tamanho: 2, threads: 3, percentualLeitura: 0},
+        {tamanho: 2, threads: 4, percentualLeitura: 100},
+        {tamanho: 5, threads: 2000, percentualLeitura: 50},
+        {tamanho: 4, threads: 3, percentualLeitura: 150},
+        {tamanho: 0, threads: 4, percentualLeitura: 80},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("tamanho=%d/threads=%d/leitura=%d", caso.tamanho, caso.threads, caso.percentualLeitura), func(t *testing.T) {
+            k := Novo(Configuracao{Tamanho: caso.tamanho, Threads: caso.threads, PercentualLeitura: caso.percentualLeitura}).(*leitoresEscritores)
+            trabalho := benchtest.Executar(t, k)
+            if esperadas := int64(caso.tamanho) * 1000; trabalho.Operacoes != esperadas {
+                t.Errorf("operacoes = %d, esperado %d", trabalho.Operacoes, esperadas)
+            }
+            chaves := len(k.armazenamento.dados)
+            switch {
+            case caso.percentualLeitura >= 100 && chaves != 0:
+                t.Errorf("%d chaves escritas com apenas leituras", chaves)
+            case caso.percentualLeitura <= 0 && caso.tamanho > 0 && chaves == 0:
+                t.Error("nenhuma chave escrita com apenas escritas")
+            }
+        })
+    }
+}
+
+func BenchmarkRw(b *testing.B) {
+    for _, threads := range []int{1, 2, 4, 8} {
+        b.Run(fmt.Sprintf("tamanho=100/threads=%d", threads), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Tamanho: 100, Threads: threads, PercentualLeitura: 80}))
+        })
+    }
+}
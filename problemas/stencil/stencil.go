@@ -0,0 +1,200 @@
+package stencil
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "runtime"
+    "sync"
+
+    "tcc-benchmarks/bench"
+)
+
+func init() {
+    bench.Registrar(func() bench.Benchmark { return Novo(ConfiguracaoPadrao()) })
+}
+
+// stencilDifusao implementa bench.Benchmark. Os campos abaixo de
+// configuracao sao o estado de uma execucao, montado por Preparar.
+type stencilDifusao struct {
+    configuracao Configuracao
+
+    tamanhoGrade int
+    totalThreads int
+    iteracoes    int
+    gradeAtual   []float64
+    proximaGrade []float64
+}
+
+// Novo devolve o benchmark de stencil com configuracao, para ser medido com
+// bench.Medir.
+func Novo(configuracao Configuracao) bench.Benchmark {
+    return &stencilDifusao{configuracao: configuracao}
+}
+
+func (k *stencilDifusao) Nome() string { return "stencil" }
+
+func (k *stencilDifusao) Descricao() string { return "Stencil 2D de 5 pontos (difusao)" }
+
+func (k *stencilDifusao) Parametros() []bench.Parametro {
+    return []bench.Parametro{
+        bench.ParametroInt(&k.configuracao.Tamanho, "size", "BENCH_SIZE", "tamanho da grade quadrada"),
+        bench.ParametroInt(&k.configuracao.Threads, "threads", "BENCH_THREADS", "numero de threads"),
+        bench.ParametroInt(&k.configuracao.Iteracoes, "iters", "BENCH_ITERS", "numero de iteracoes"),
+    }
+}
+
+// Preparar aloca as duas grades, com a atual inteira em 1.0.
+func (k *stencilDifusao) Preparar(ctx context.Context) error {
+    tamanhoGrade := max(3, k.configuracao.Tamanho)
+    k.tamanhoGrade = tamanhoGrade
+    k.totalThreads = max(1, k.configuracao.Threads)
+    k.iteracoes = max(1, k.configuracao.Iteracoes)
+    runtime.GOMAXPROCS(k.totalThreads)
+    k.gradeAtual = make([]float64, tamanhoGrade*tamanhoGrade)
+    k.proximaGrade = make([]float64, tamanhoGrade*tamanhoGrade)
+    for indice := range k.gradeAtual {
+        k.gradeAtual[indice] = 1.0
+    }
+    return nil
+}
+
+func (k *stencilDifusao) Executar(ctx context.Context) (bench.Trabalho, error) {
+    tamanhoGrade, totalThreads, iteracoes := k.tamanhoGrade, k.totalThreads, k.iteracoes
+    gradeAtual, proximaGrade := k.gradeAtual, k.proximaGrade
+    calcularIndice := func(linha, coluna int) int {
+        return linha*tamanhoGrade + coluna
+    }
+    type tarefa struct {
+        linha          int
+        gradeAtual     []float64
+        proximaGrade   []float64
+        grupoSincronia *sync.WaitGroup
+    }
+    trabalhos := make(chan tarefa, totalThreads)
+    for worker := 0; worker < totalThreads; worker++ {
+        indiceWorker := worker
+        go func() {
+            defer bench.VincularWorker(indiceWorker, totalThreads)()
+            for job := range trabalhos {
+                for coluna := 1; coluna < tamanhoGrade-1; coluna++ {
+                    somaVizinhos := job.gradeAtual[calcularIndice(job.linha-1, coluna)] +
+                        job.gradeAtual[calcularIndice(job.linha+1, coluna)] +
+                        job.gradeAtual[calcularIndice(job.linha, coluna-1)] +
+                        job.gradeAtual[calcularIndice(job.linha, coluna+1)]
+                    job.proximaGrade[calcularIndice(job.linha, coluna)] = 0.25 * somaVizinhos
+                }
+                job.grupoSincronia.Done()
+            }
+        }()
+    }
+    ciclosConcluidos := 0
+    for ; ciclosConcluidos < iteracoes && !bench.Interrompido(ctx); ciclosConcluidos++ {
+        var grupo sync.WaitGroup
+        for linha := 1; linha < tamanhoGrade-1; linha++ {
+            grupo.Add(1)
+            trabalhos <- tarefa{
+                linha:          linha,
+                gradeAtual:     gradeAtual,
+                proximaGrade:   proximaGrade,
+                grupoSincronia: &grupo,
+            }
+        }
+        grupo.Wait()
+        gradeAtual, proximaGrade = proximaGrade, gradeAtual
+    }
+    close(trabalhos)
+    k.gradeAtual, k.proximaGrade = gradeAtual, proximaGrade
+    celulas := max(0, tamanhoGrade-2)
+    celulas64 := int64(celulas)
+    itensProcessados := celulas64 * celulas64 * int64(ciclosConcluidos)
+    return bench.Trabalho{Tamanho: tamanhoGrade, Threads: totalThreads, Itens: itensProcessados, Iteracoes: int64(ciclosConcluidos)}, nil
+}
+
+// Verificar confere que o interior da grade final ficou entre 0 e 1: cada
+// celula e a media de vizinhos que comecam nesse intervalo.
+func (k *stencilDifusao) Verificar() error {
+    for linha := 1; linha < k.tamanhoGrade-1; linha++ {
+        for coluna := 1; coluna < k.tamanhoGrade-1; coluna++ {
+            valor := k.gradeAtual[linha*k.tamanhoGrade+coluna]
+            if math.IsNaN(valor) || valor < 0 || valor > 1 {
+                return fmt.Errorf("celula (%d, %d) = %g fora de [0, 1]", linha, coluna, valor)
+            }
+        }
+    }
+    return nil
+}
+
+// Checksum e a norma de Frobenius da grade final.
+func (k *stencilDifusao) Checksum() bench.Checksum {
+    return normaGrade(k.gradeAtual)
+}
+
+// ChecksumReferencia aplica as iteracoes em sequencia sobre um par de grades
+// proprio, com as mesmas condicoes iniciais de Preparar.
+func (k *stencilDifusao) ChecksumReferencia(ctx context.Context) (bench.Checksum, error) {
+    n := k.tamanhoGrade
+    atual := make([]float64, n*n)
+    proxima := make([]float64, n*n)
+    for indice := range atual {
+        atual[indice] = 1.0
+    }
+    for iteracao := 0; iteracao < k.iteracoes; iteracao++ {
+        if bench.Interrompido(ctx) {
+            return bench.Checksum{}, ctx.Err()
+        }
+        for linha := 1; linha < n-1; linha++ {
+            for coluna := 1; coluna < n-1; coluna++ {
+                proxima[linha*n+coluna] = 0.25 * (atual[(linha-1)*n+coluna] + atual[(linha+1)*n+coluna] + atual[linha*n+coluna-1] + atual[linha*n+coluna+1])
+            }
+        }
+        atual, proxima = proxima, atual
+    }
+    return normaGrade(atual), nil
+}
+
+func normaGrade(grade []float64) bench.Checksum {
+    somaQuadrados := 0.0
+    for _, valor := range grade {
+        somaQuadrados += valor * valor
+    }
+    return bench.ChecksumReal(math.Sqrt(somaQuadrados), 1e-9)
+}
+
+func (k *stencilDifusao) Encerrar() error {
+    k.gradeAtual, k.proximaGrade = nil, nil
+    return nil
+}
+
+// Configuracao descreve uma execucao do stencil de difusao.
+type Configuracao struct {
+    // Tamanho e o lado da grade quadrada, incluindo a borda fixa.
+    Tamanho   int
+    Threads   int
+    Iteracoes int
+}
+
+// ConfiguracaoPadrao devolve os valores usados pelo benchctl sem flags.
+func ConfiguracaoPadrao() Configuracao {
+    return Configuracao{Tamanho: 1024, Threads: runtime.NumCPU(), Iteracoes: 100}
+}
+
+// Resultado e o que Executar devolve: as metricas da regiao medida.
+type Resultado struct {
+    bench.MetricasBenchmark
+}
+
+// Executar mede uma execucao com configuracao e ajusta runtime.GOMAXPROCS
+// para configuracao.Threads. Se ctx for cancelado o kernel para ao fim da
+// iteracao corrente e o resultado e parcial.
+func Executar(ctx context.Context, configuracao Configuracao) (Resultado, error) {
+    metricas, err := bench.Medir(ctx, Novo(configuracao))
+    return Resultado{metricas}, err
+}
+
+func max(a, b int) int {
+    if a > b {
+        return a
+    }
+    return b
+}
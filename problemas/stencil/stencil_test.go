@@ -0,0 +1,68 @@
+package stencil
+
+import (
+    "fmt"
+    "testing"
+
+    "tcc-benchmarks/bench/benchtest"
+)
+
+// stencilReferencia aplica sequencialmente iteracoes passos do stencil de 5
+// pontos com o mesmo par de grades (a atual toda em 1.0, a proxima zerada) e
+// devolve a grade final.
+func stencilReferencia(n, iteracoes int) []float64 {
+    atual := make([]float64, n*n)
+    proxima := make([]float64, n*n)
+    for indice := range atual {
+        atual[indice] = 1.0
+    }
+    for passo := 0; passo < iteracoes; passo++ {
+        for linha := 1; linha < n-1; linha++ {
+            for coluna := 1; coluna < n-1; coluna++ {
+                proxima[linha*n+coluna] = 0.25 * (atual[(linha-1)*n+coluna] + atual[(linha+1)*n+coluna] + atual[linha*n+coluna-1] + atual[linha*n+coluna+1])
+            }
+        }
+        atual, proxima = proxima, atual
+    }
+    return atual
+}
+
+func TestStencilContraReferencia(t *testing.T) {
+    casos := []struct {
+        tamanho   int
+        threads   int
+        iteracoes int
+    }{
+        {tamanho: 3, threads: 1, iteracoes: 1},
+        {tamanho: 8, threads: 2, iteracoes: 3},
+        {tamanho: 17, threads: 3, iteracoes: 10},
+        {tamanho: 64, threads: 4, iteracoes: 25},
+        {tamanho: 65, threads: 8, iteracoes: 2},
+    }
+    for _, caso := range casos {
+        t.Run(fmt.Sprintf("n=%d/threads=%d/iters=%d", caso.tamanho, caso.threads, caso.iteracoes), func(t *testing.T) {
+            k := Novo(Configuracao{Tamanho: caso.tamanho, Threads: caso.threads, Iteracoes: caso.iteracoes}).(*stencilDifusao)
+            trabalho := benchtest.Executar(t, k)
+            esperado := stencilReferencia(caso.tamanho, caso.iteracoes)
+            for indice, valor := range k.gradeAtual {
+                if valor != esperado[indice] {
+                    t.Fatalf("celula (%d, %d) = %g, esperado %g", indice/caso.tamanho, indice%caso.tamanho, valor, esperado[indice])
+                }
+            }
+            if trabalho.Iteracoes != int64(caso.iteracoes) {
+                t.Errorf("iteracoes = %d, esperado %d", trabalho.Iteracoes, caso.iteracoes)
+            }
+            if celulas := int64(caso.tamanho-2) * int64(caso.tamanho-2) * int64(caso.iteracoes); trabalho.Itens != celulas {
+                t.Errorf("itens = %d, esperado %d", trabalho.Itens, celulas)
+            }
+        })
+    }
+}
+
+func BenchmarkStencil(b *testing.B) {
+    for _, threads := range []int{1, 2, 4, 8} {
+        b.Run(fmt.Sprintf("n=512/iters=10/threads=%d", threads), func(b *testing.B) {
+            benchtest.Medir(b, Novo(Configuracao{Tamanho: 512, Threads: threads, Iteracoes: 10}))
+        })
+    }
+}
@@ -0,0 +1,13 @@
+// Package todos importa todos os problemas em Go para que se registrem no
+// pacote bench. Um problema novo so precisa de uma linha aqui para aparecer no
+// benchctl.
+package todos
+
+import (
+    _ "tcc-benchmarks/problemas/matmul"
+    _ "tcc-benchmarks/problemas/mcpi"
+    _ "tcc-benchmarks/problemas/pc"
+    _ "tcc-benchmarks/problemas/phil"
+    _ "tcc-benchmarks/problemas/rw"
+    _ "tcc-benchmarks/problemas/stencil"
+)
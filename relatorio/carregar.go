@@ -0,0 +1,67 @@
+// Package relatorio le arquivos de resultados dos benchmarks e gera graficos
+// e planilhas a partir deles.
+package relatorio
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+
+    "tcc-benchmarks/bench"
+)
+
+// CarregarResultados le um arquivo com objetos e arrays JSON em sequencia,
+// o que cobre um objeto, um array, um objeto por linha (JSONL) e os documentos
+// acrescentados por run --format json --out. Linhas de erro, sem nome_problema, e resultados
+// parciais, com status diferente de ok, sao ignorados; resultados sem status
+// (Rust e Java) contam como completos.
+func CarregarResultados(caminho string) ([]bench.MetricasBenchmark, error) {
+    dados, err := os.ReadFile(caminho)
+    if err != nil {
+        return nil, err
+    }
+    resultados, err := decodificarResultados(dados)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w", caminho, err)
+    }
+    return resultados, nil
+}
+
+func decodificarResultados(dados []byte) ([]bench.MetricasBenchmark, error) {
+    var lidos []bench.MetricasBenchmark
+    decodificador := json.NewDecoder(bytes.NewReader(dados))
+    for {
+        var documento json.RawMessage
+        err := decodificador.Decode(&documento)
+        if err == io.EOF {
+            break
+        }
+        if err != nil {
+            return nil, err
+        }
+        // run --format json --out acrescenta um documento por invocacao, e a
+        // varredura grava cada uma como array: os arrays sao achatados.
+        if documento[0] == '[' {
+            var itens []bench.MetricasBenchmark
+            if err := json.Unmarshal(documento, &itens); err != nil {
+                return nil, err
+            }
+            lidos = append(lidos, itens...)
+            continue
+        }
+        var metricas bench.MetricasBenchmark
+        if err := json.Unmarshal(documento, &metricas); err != nil {
+            return nil, err
+        }
+        lidos = append(lidos, metricas)
+    }
+    resultados := lidos[:0]
+    for _, metricas := range lidos {
+        if metricas.Problema != "" && (metricas.Status == "" || metricas.Status == bench.StatusConcluido) {
+            resultados = append(resultados, metricas)
+        }
+    }
+    return resultados, nil
+}
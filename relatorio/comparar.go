@@ -0,0 +1,204 @@
+package relatorio
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sort"
+    "text/tabwriter"
+
+    "tcc-benchmarks/bench"
+)
+
+type ChaveComparacao struct {
+    Problema string `json:"nome_problema"`
+    Tamanho  int    `json:"tamanho_instancia"`
+    Threads  int    `json:"quantidade_threads"`
+}
+
+type Comparacao struct {
+    ChaveComparacao
+    AmostrasAntigas int      `json:"amostras_antigas"`
+    AmostrasNovas   int      `json:"amostras_novas"`
+    MediaAntiga     float64  `json:"media_antiga"`
+    MediaNova       float64  `json:"media_nova"`
+    DesvioAntigo    float64  `json:"desvio_antigo"`
+    DesvioNovo      float64  `json:"desvio_novo"`
+    Delta           float64  `json:"delta"`
+    VariacaoPct     float64  `json:"variacao_pct"`
+    ValorP          *float64 `json:"valor_p"`
+    Significativo   bool     `json:"significativo"`
+    // Conclusivo indica se o teste consegue chegar a alfa com este numero de
+    // amostras; com 1 contra 5 repeticoes o Mann-Whitney nunca fica abaixo
+    // de 0,33, por exemplo.
+    Conclusivo bool `json:"teste_conclusivo"`
+}
+
+var MetricasComparaveis = []string{"tempo_decorrido_ms", "tempo_cpu_ms"}
+
+var TestesSignificancia = []string{"mannwhitney", "welch"}
+
+// extrairAmostras devolve os valores brutos da metrica: as repeticoes
+// guardadas em amostras, quando existem, ou o valor agregado da linha.
+func extrairAmostras(metricas bench.MetricasBenchmark, metrica string) []float64 {
+    valores := make([]float64, 0, len(metricas.Amostras))
+    for _, amostra := range metricas.Amostras {
+        if metrica == "tempo_cpu_ms" {
+            valores = append(valores, amostra.CpuMs)
+        } else {
+            valores = append(valores, amostra.ParedeMs)
+        }
+    }
+    if len(valores) > 0 {
+        return valores
+    }
+    if metrica == "tempo_cpu_ms" {
+        return []float64{metricas.CpuMs}
+    }
+    return []float64{metricas.ParedeMs}
+}
+
+func AgruparAmostras(resultados []bench.MetricasBenchmark, metrica string) map[ChaveComparacao][]float64 {
+    grupos := map[ChaveComparacao][]float64{}
+    for _, metricas := range resultados {
+        chave := ChaveComparacao{Problema: metricas.Problema, Tamanho: metricas.Tamanho, Threads: metricas.Threads}
+        grupos[chave] = append(grupos[chave], extrairAmostras(metricas, metrica)...)
+    }
+    return grupos
+}
+
+// Comparar agrupa os dois conjuntos de resultados por (problema, tamanho,
+// threads) e, para cada grupo presente em ambos, calcula a variacao da media
+// e o valor p do teste escolhido. Sem amostras suficientes o valor p fica nulo
+// e a diferenca nunca e marcada como significativa; quando o menor valor p
+// possivel para os tamanhos das amostras nao fica abaixo de alfa, a
+// comparacao e marcada como inconclusiva.
+func Comparar(antigos, novos []bench.MetricasBenchmark, metrica, teste string, alfa float64) ([]Comparacao, error) {
+    if !contem(MetricasComparaveis, metrica) {
+        return nil, fmt.Errorf("metrica nao comparavel: %q", metrica)
+    }
+    if !contem(TestesSignificancia, teste) {
+        return nil, fmt.Errorf("teste de significancia desconhecido: %q", teste)
+    }
+    gruposAntigos := AgruparAmostras(antigos, metrica)
+    gruposNovos := AgruparAmostras(novos, metrica)
+    var comparacoes []Comparacao
+    for chave, amostrasAntigas := range gruposAntigos {
+        amostrasNovas, existe := gruposNovos[chave]
+        if !existe {
+            continue
+        }
+        comparacao := Comparacao{
+            ChaveComparacao: chave,
+            AmostrasAntigas: len(amostrasAntigas),
+            AmostrasNovas:   len(amostrasNovas),
+            MediaAntiga:     bench.Media(amostrasAntigas),
+            MediaNova:       bench.Media(amostrasNovas),
+            DesvioAntigo:    bench.DesvioPadrao(amostrasAntigas),
+            DesvioNovo:      bench.DesvioPadrao(amostrasNovas),
+        }
+        comparacao.Delta = comparacao.MediaNova - comparacao.MediaAntiga
+        if comparacao.MediaAntiga != 0 {
+            comparacao.VariacaoPct = comparacao.Delta / comparacao.MediaAntiga * 100
+        }
+        valorP := bench.TesteMannWhitney(amostrasAntigas, amostrasNovas)
+        if teste == "welch" {
+            valorP = bench.TesteWelch(amostrasAntigas, amostrasNovas)
+        }
+        if !math.IsNaN(valorP) {
+            comparacao.ValorP = &valorP
+            comparacao.Significativo = valorP < alfa
+            comparacao.Conclusivo = valorPMinimo(teste, len(amostrasAntigas), len(amostrasNovas)) < alfa
+        }
+        comparacoes = append(comparacoes, comparacao)
+    }
+    OrdenarComparacoes(comparacoes)
+    return comparacoes, nil
+}
+
+// valorPMinimo devolve o menor valor p que o teste consegue produzir com n1 e
+// n2 amostras. No Mann-Whitney e o das amostras totalmente separadas; o t de
+// Welch chega a zero sempre que ha pelo menos duas amostras de cada lado.
+func valorPMinimo(teste string, n1, n2 int) float64 {
+    if teste == "welch" {
+        return 0
+    }
+    separadasA := make([]float64, n1)
+    separadasB := make([]float64, n2)
+    for indice := range separadasA {
+        separadasA[indice] = float64(indice)
+    }
+    for indice := range separadasB {
+        separadasB[indice] = float64(n1 + indice)
+    }
+    return bench.TesteMannWhitney(separadasA, separadasB)
+}
+
+func OrdenarComparacoes(comparacoes []Comparacao) {
+    sort.Slice(comparacoes, func(i, j int) bool {
+        a, b := comparacoes[i].ChaveComparacao, comparacoes[j].ChaveComparacao
+        if a.Problema != b.Problema {
+            return a.Problema < b.Problema
+        }
+        if a.Tamanho != b.Tamanho {
+            return a.Tamanho < b.Tamanho
+        }
+        return a.Threads < b.Threads
+    })
+}
+
+func contem(opcoes []string, valor string) bool {
+    for _, opcao := range opcoes {
+        if opcao == valor {
+            return true
+        }
+    }
+    return false
+}
+
+// EscreverTabelaComparacao imprime as comparacoes no estilo do benchstat: media
+// antiga e nova com o desvio relativo, a variacao, o valor p e se a diferenca
+// e significativa ("~" quando nao e, "n/d" quando o teste e inconclusivo).
+func EscreverTabelaComparacao(saida io.Writer, comparacoes []Comparacao, metrica string) error {
+    tabela := tabwriter.NewWriter(saida, 0, 4, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintf(tabela, "problema\ttamanho\tthreads\tantigo %s\t\tnovo %s\t\tvariacao\tvalor p\tsignificativo\t\n", metrica, metrica)
+    for _, comparacao := range comparacoes {
+        valorP := "n/d"
+        if comparacao.ValorP != nil {
+            valorP = fmt.Sprintf("%.3f", *comparacao.ValorP)
+        }
+        significativo := "~"
+        if comparacao.Significativo {
+            significativo = "sim"
+        } else if !comparacao.Conclusivo {
+            significativo = "n/d"
+        }
+        fmt.Fprintf(tabela, "%s\t%d\t%d\t%.3f\t±%s\t%.3f\t±%s\t%+.2f%%\t%s (n=%d+%d)\t%s\t\n",
+            comparacao.Problema, comparacao.Tamanho, comparacao.Threads,
+            comparacao.MediaAntiga, desvioRelativo(comparacao.DesvioAntigo, comparacao.MediaAntiga),
+            comparacao.MediaNova, desvioRelativo(comparacao.DesvioNovo, comparacao.MediaNova),
+            comparacao.VariacaoPct, valorP, comparacao.AmostrasAntigas, comparacao.AmostrasNovas, significativo)
+    }
+    return tabela.Flush()
+}
+
+func desvioRelativo(desvio, media float64) string {
+    if media == 0 {
+        return "0%"
+    }
+    return fmt.Sprintf("%.0f%%", desvio/media*100)
+}
+
+// Regressoes seleciona as comparacoes em que a media nova ficou mais lenta
+// que a antiga alem de toleranciaPct por cento e a diferenca e significativa.
+// Grupos em que o teste e inconclusivo (valor p nulo, ou amostras poucas
+// demais para o valor p chegar a alfa) dependem so da tolerancia.
+func Regressoes(comparacoes []Comparacao, toleranciaPct float64) []Comparacao {
+    var regressoes []Comparacao
+    for _, comparacao := range comparacoes {
+        if comparacao.VariacaoPct > toleranciaPct && (!comparacao.Conclusivo || comparacao.Significativo) {
+            regressoes = append(regressoes, comparacao)
+        }
+    }
+    return regressoes
+}
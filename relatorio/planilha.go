@@ -0,0 +1,59 @@
+package relatorio
+
+import (
+    "tcc-benchmarks/bench"
+)
+
+// MontarPlanilhas organiza os resultados em uma aba por nome_problema. As
+// colunas seguem os campos escalares de MetricasBenchmark, precedidas da serie
+// (rotulo do arquivo de origem); speedup e eficiencia sao recalculados por
+// serie e tamanho em relacao a media do ponto com menos threads, com a mesma
+// formula da varredura.
+func MontarPlanilhas(rotulos []string, resultados [][]bench.MetricasBenchmark) []Planilha {
+    campos := bench.CamposTabulares()
+    cabecalho := []string{"serie"}
+    for _, campo := range campos {
+        cabecalho = append(cabecalho, campo.Nome)
+    }
+    indicePorProblema := map[string]int{}
+    var planilhas []Planilha
+    for indiceSerie, rotulo := range rotulos {
+        referencias := pontosReferencia(resultados[indiceSerie])
+        for _, metricas := range resultados[indiceSerie] {
+            metricas.Speedup, metricas.Eficiencia = 0, 0
+            referencia := referencias[ChaveGrafico{Problema: metricas.Problema, Tamanho: metricas.Tamanho}]
+            bench.AplicarSpeedup(&metricas, referencia.TempoMs, referencia.Threads)
+            indice, existe := indicePorProblema[metricas.Problema]
+            if !existe {
+                indice = len(planilhas)
+                indicePorProblema[metricas.Problema] = indice
+                planilhas = append(planilhas, Planilha{Nome: metricas.Problema, Cabecalho: cabecalho})
+            }
+            linha := make([]any, 0, len(cabecalho))
+            linha = append(linha, rotulo)
+            for _, campo := range campos {
+                linha = append(linha, campo.Valor(metricas))
+            }
+            planilhas[indice].Linhas = append(planilhas[indice].Linhas, linha)
+        }
+    }
+    return planilhas
+}
+
+// pontosReferencia devolve, por problema e tamanho, o ponto com menos threads
+// da serie: o tempo medio e as threads usadas como referencia do speedup.
+func pontosReferencia(resultados []bench.MetricasBenchmark) map[ChaveGrafico]PontoSerie {
+    grupos := map[ChaveGrafico][]bench.MetricasBenchmark{}
+    for _, metricas := range resultados {
+        chave := ChaveGrafico{Problema: metricas.Problema, Tamanho: metricas.Tamanho}
+        grupos[chave] = append(grupos[chave], metricas)
+    }
+    referencias := map[ChaveGrafico]PontoSerie{}
+    for chave, grupo := range grupos {
+        serie := MontarSerie("", grupo)
+        if len(serie.Pontos) > 0 {
+            referencias[chave] = serie.Pontos[0]
+        }
+    }
+    return referencias
+}
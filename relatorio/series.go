@@ -0,0 +1,76 @@
+package relatorio
+
+import (
+    "sort"
+
+    "tcc-benchmarks/bench"
+)
+
+type PontoSerie struct {
+    Threads    int
+    TempoMs    float64
+    Speedup    float64
+    Eficiencia float64
+}
+
+type Serie struct {
+    Rotulo string
+    Pontos []PontoSerie
+}
+
+// ChaveGrafico identifica um grafico: um problema numa escala de instancia.
+type ChaveGrafico struct {
+    Problema string
+    Tamanho  int
+}
+
+// MontarSerie agrega os resultados de um mesmo problema e tamanho por numero
+// de threads, usando a media dos tempos quando ha mais de uma linha por ponto.
+// O speedup toma como referencia o ponto com menos threads (normalmente 1, 2
+// no pc e no phil) e a eficiencia segue a formula da varredura, valendo 1 na
+// referencia.
+func MontarSerie(rotulo string, resultados []bench.MetricasBenchmark) Serie {
+    tempos := map[int][]float64{}
+    for _, metricas := range resultados {
+        tempos[metricas.Threads] = append(tempos[metricas.Threads], metricas.ParedeMs)
+    }
+    serie := Serie{Rotulo: rotulo}
+    for threads, valores := range tempos {
+        serie.Pontos = append(serie.Pontos, PontoSerie{Threads: threads, TempoMs: bench.Media(valores)})
+    }
+    sort.Slice(serie.Pontos, func(i, j int) bool { return serie.Pontos[i].Threads < serie.Pontos[j].Threads })
+    if len(serie.Pontos) == 0 {
+        return serie
+    }
+    referencia := serie.Pontos[0]
+    for indice := range serie.Pontos {
+        ponto := &serie.Pontos[indice]
+        ponto.Speedup, ponto.Eficiencia = bench.CalcularSpeedup(ponto.TempoMs, ponto.Threads, referencia.TempoMs, referencia.Threads)
+    }
+    return serie
+}
+
+// AgruparPorGrafico separa os resultados de cada arquivo rotulado por
+// problema e tamanho, devolvendo as series de cada grafico.
+func AgruparPorGrafico(rotulos []string, resultados [][]bench.MetricasBenchmark) (map[ChaveGrafico][]Serie, []ChaveGrafico) {
+    graficos := map[ChaveGrafico][]Serie{}
+    var chaves []ChaveGrafico
+    for indice, rotulo := range rotulos {
+        grupos := map[ChaveGrafico][]bench.MetricasBenchmark{}
+        var ordem []ChaveGrafico
+        for _, metricas := range resultados[indice] {
+            chave := ChaveGrafico{Problema: metricas.Problema, Tamanho: metricas.Tamanho}
+            if _, existe := grupos[chave]; !existe {
+                ordem = append(ordem, chave)
+            }
+            grupos[chave] = append(grupos[chave], metricas)
+        }
+        for _, chave := range ordem {
+            if _, existe := graficos[chave]; !existe {
+                chaves = append(chaves, chave)
+            }
+            graficos[chave] = append(graficos[chave], MontarSerie(rotulo, grupos[chave]))
+        }
+    }
+    return graficos, chaves
+}
@@ -0,0 +1,189 @@
+package relatorio
+
+import (
+    "bufio"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "math"
+    "strconv"
+    "strings"
+)
+
+type TipoGrafico int
+
+const (
+    GraficoTempo TipoGrafico = iota
+    GraficoSpeedup
+    GraficoEficiencia
+)
+
+var TiposGrafico = []TipoGrafico{GraficoTempo, GraficoSpeedup, GraficoEficiencia}
+
+func (tipo TipoGrafico) Nome() string {
+    switch tipo {
+    case GraficoSpeedup:
+        return "Speedup"
+    case GraficoEficiencia:
+        return "Eficiencia"
+    default:
+        return "Tempo"
+    }
+}
+
+func (tipo TipoGrafico) rotuloEixoY() string {
+    switch tipo {
+    case GraficoSpeedup:
+        return "Speedup"
+    case GraficoEficiencia:
+        return "Eficiência"
+    default:
+        return "Tempo (ms)"
+    }
+}
+
+func (tipo TipoGrafico) valor(ponto PontoSerie) float64 {
+    switch tipo {
+    case GraficoSpeedup:
+        return ponto.Speedup
+    case GraficoEficiencia:
+        return ponto.Eficiencia
+    default:
+        return ponto.TempoMs
+    }
+}
+
+// ideal devolve a curva de referencia do grafico: speedup linear e eficiencia
+// 1. O grafico de tempo nao tem referencia.
+func (tipo TipoGrafico) ideal(threads float64) (float64, bool) {
+    switch tipo {
+    case GraficoSpeedup:
+        return threads, true
+    case GraficoEficiencia:
+        return 1, true
+    default:
+        return 0, false
+    }
+}
+
+var coresSeries = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b", "#e377c2", "#17becf"}
+
+const (
+    larguraSVG     = 800.0
+    alturaSVG      = 500.0
+    margemEsquerda = 80.0
+    margemDireita  = 170.0
+    margemSuperior = 50.0
+    margemInferior = 60.0
+)
+
+// escalaAgradavel arredonda o maximo do eixo para um multiplo de 1, 2 ou 5
+// vezes uma potencia de 10, devolvendo o limite e o passo das marcacoes.
+func escalaAgradavel(maximo float64, divisoes int) (float64, float64) {
+    if maximo <= 0 || math.IsNaN(maximo) || math.IsInf(maximo, 0) {
+        return 1, 1 / float64(divisoes)
+    }
+    bruto := maximo / float64(divisoes)
+    magnitude := math.Pow(10, math.Floor(math.Log10(bruto)))
+    passo := 10 * magnitude
+    switch residuo := bruto / magnitude; {
+    case residuo <= 1:
+        passo = magnitude
+    case residuo <= 2:
+        passo = 2 * magnitude
+    case residuo <= 5:
+        passo = 5 * magnitude
+    }
+    return math.Ceil(maximo/passo-1e-9) * passo, passo
+}
+
+func formatarMarcacao(valor, passo float64) string {
+    casas := 0
+    if passo < 1 {
+        casas = int(math.Ceil(-math.Log10(passo) - 1e-9))
+    }
+    return strconv.FormatFloat(valor, 'f', casas, 64)
+}
+
+func escaparXML(texto string) string {
+    var construtor strings.Builder
+    _ = xml.EscapeText(&construtor, []byte(texto))
+    return construtor.String()
+}
+
+// EscreverGraficoSVG desenha um grafico de linhas Threads x tipo com uma linha
+// por serie e, para speedup e eficiencia, a referencia ideal tracejada.
+func EscreverGraficoSVG(saida io.Writer, titulo string, tipo TipoGrafico, series []Serie) error {
+    maximoThreads, maximoY := 1.0, 0.0
+    for _, serie := range series {
+        for _, ponto := range serie.Pontos {
+            maximoThreads = math.Max(maximoThreads, float64(ponto.Threads))
+            maximoY = math.Max(maximoY, tipo.valor(ponto))
+        }
+    }
+    if referencia, ok := tipo.ideal(maximoThreads); ok {
+        maximoY = math.Max(maximoY, referencia)
+    }
+    limiteX, passoX := escalaAgradavel(maximoThreads, 8)
+    if passoX < 1 {
+        limiteX, passoX = math.Max(1, math.Ceil(maximoThreads)), 1
+    }
+    limiteY, passoY := escalaAgradavel(maximoY*1.05, 6)
+
+    areaLargura := larguraSVG - margemEsquerda - margemDireita
+    areaAltura := alturaSVG - margemSuperior - margemInferior
+    posicaoX := func(valor float64) float64 { return margemEsquerda + valor/limiteX*areaLargura }
+    posicaoY := func(valor float64) float64 { return margemSuperior + areaAltura - valor/limiteY*areaAltura }
+
+    escritor := bufio.NewWriter(saida)
+    fmt.Fprintf(escritor, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="12">`+"\n", larguraSVG, alturaSVG, larguraSVG, alturaSVG)
+    fmt.Fprintf(escritor, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+    fmt.Fprintf(escritor, `<text x="%.1f" y="28" font-size="16" text-anchor="middle">%s</text>`+"\n", margemEsquerda+areaLargura/2, escaparXML(titulo))
+
+    for valor := 0.0; valor <= limiteY+passoY/2; valor += passoY {
+        y := posicaoY(valor)
+        fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", margemEsquerda, y, margemEsquerda+areaLargura, y)
+        fmt.Fprintf(escritor, `<text x="%.1f" y="%.1f" text-anchor="end" dominant-baseline="middle">%s</text>`+"\n", margemEsquerda-6, y, formatarMarcacao(valor, passoY))
+    }
+    for valor := 0.0; valor <= limiteX+passoX/2; valor += passoX {
+        x := posicaoX(valor)
+        fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", x, margemSuperior, x, margemSuperior+areaAltura)
+        fmt.Fprintf(escritor, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", x, margemSuperior+areaAltura+18, formatarMarcacao(valor, passoX))
+    }
+    fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black"/>`+"\n", margemEsquerda, margemSuperior+areaAltura, margemEsquerda+areaLargura, margemSuperior+areaAltura)
+    fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="black"/>`+"\n", margemEsquerda, margemSuperior, margemEsquerda, margemSuperior+areaAltura)
+    fmt.Fprintf(escritor, `<text x="%.1f" y="%.1f" text-anchor="middle">Threads</text>`+"\n", margemEsquerda+areaLargura/2, alturaSVG-15)
+    fmt.Fprintf(escritor, `<text x="20" y="%.1f" text-anchor="middle" transform="rotate(-90 20 %.1f)">%s</text>`+"\n", margemSuperior+areaAltura/2, margemSuperior+areaAltura/2, escaparXML(tipo.rotuloEixoY()))
+
+    legendaX := margemEsquerda + areaLargura + 20
+    legendaY := margemSuperior + 10
+    if _, ok := tipo.ideal(1); ok {
+        inicio, _ := tipo.ideal(0)
+        fimX := limiteX
+        fimY, _ := tipo.ideal(fimX)
+        if fimY > limiteY {
+            // so o speedup ideal cresce com as threads: corta a reta no topo do grafico
+            fimX, fimY = limiteY, limiteY
+        }
+        fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#888888" stroke-dasharray="6 4"/>`+"\n", posicaoX(0), posicaoY(inicio), posicaoX(fimX), posicaoY(fimY))
+        fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#888888" stroke-dasharray="6 4"/>`+"\n", legendaX, legendaY, legendaX+24, legendaY)
+        fmt.Fprintf(escritor, `<text x="%.1f" y="%.1f" dominant-baseline="middle">ideal</text>`+"\n", legendaX+30, legendaY)
+        legendaY += 20
+    }
+    for indice, serie := range series {
+        cor := coresSeries[indice%len(coresSeries)]
+        pontos := make([]string, 0, len(serie.Pontos))
+        for _, ponto := range serie.Pontos {
+            pontos = append(pontos, fmt.Sprintf("%.1f,%.1f", posicaoX(float64(ponto.Threads)), posicaoY(tipo.valor(ponto))))
+        }
+        fmt.Fprintf(escritor, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(pontos, " "), cor)
+        for _, ponto := range serie.Pontos {
+            fmt.Fprintf(escritor, `<circle cx="%.1f" cy="%.1f" r="3.5" fill="%s"><title>%d threads: %s</title></circle>`+"\n", posicaoX(float64(ponto.Threads)), posicaoY(tipo.valor(ponto)), cor, ponto.Threads, strconv.FormatFloat(tipo.valor(ponto), 'g', 6, 64))
+        }
+        fmt.Fprintf(escritor, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="2"/>`+"\n", legendaX, legendaY, legendaX+24, legendaY, cor)
+        fmt.Fprintf(escritor, `<text x="%.1f" y="%.1f" dominant-baseline="middle">%s</text>`+"\n", legendaX+30, legendaY, escaparXML(serie.Rotulo))
+        legendaY += 20
+    }
+    fmt.Fprintln(escritor, "</svg>")
+    return escritor.Flush()
+}
@@ -0,0 +1,191 @@
+package relatorio
+
+import (
+    "archive/zip"
+    "bufio"
+    "fmt"
+    "io"
+    "math"
+    "strconv"
+    "strings"
+)
+
+type Planilha struct {
+    Nome      string
+    Cabecalho []string
+    Linhas    [][]any
+}
+
+const (
+    espacoPlanilha = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+    espacoRelacoes = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+    cabecalhoXML   = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+)
+
+const estilosXLSX = cabecalhoXML + `<styleSheet xmlns="` + espacoPlanilha + `">` +
+    `<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+    `<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+    `<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+    `<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+    `<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+    `</styleSheet>`
+
+// nomeColuna converte um indice a partir de zero na letra de coluna do Excel.
+func nomeColuna(indice int) string {
+    nome := ""
+    for indice >= 0 {
+        nome = string(rune('A'+indice%26)) + nome
+        indice = indice/26 - 1
+    }
+    return nome
+}
+
+// nomesPlanilhasValidos aplica as restricoes do Excel aos nomes das abas:
+// ate 31 caracteres, sem []:*?/\ e sem repeticoes.
+func nomesPlanilhasValidos(planilhas []Planilha) []string {
+    usados := map[string]bool{}
+    nomes := make([]string, len(planilhas))
+    for indice, planilha := range planilhas {
+        base := strings.Map(func(caractere rune) rune {
+            if strings.ContainsRune(`[]:*?/\`, caractere) {
+                return '_'
+            }
+            return caractere
+        }, planilha.Nome)
+        if base == "" {
+            base = "Planilha"
+        }
+        nome := base
+        for sufixo := 2; ; sufixo++ {
+            if len([]rune(nome)) > 31 {
+                nome = string([]rune(nome)[:31])
+            }
+            if !usados[strings.ToLower(nome)] {
+                break
+            }
+            complemento := fmt.Sprintf(" (%d)", sufixo)
+            nome = string([]rune(base)[:min(len([]rune(base)), 31-len(complemento))]) + complemento
+        }
+        usados[strings.ToLower(nome)] = true
+        nomes[indice] = nome
+    }
+    return nomes
+}
+
+// EscreverXLSX grava uma pasta de trabalho Office Open XML com uma aba por
+// planilha, usando apenas archive/zip. Textos vao como inline strings.
+func EscreverXLSX(saida io.Writer, planilhas []Planilha) error {
+    compactador := zip.NewWriter(saida)
+    nomes := nomesPlanilhasValidos(planilhas)
+
+    var tipos strings.Builder
+    tipos.WriteString(cabecalhoXML + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+    tipos.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+    tipos.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+    tipos.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+    tipos.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+    for indice := range planilhas {
+        fmt.Fprintf(&tipos, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, indice+1)
+    }
+    tipos.WriteString(`</Types>`)
+
+    relacoesRaiz := cabecalhoXML + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+        `<Relationship Id="rId1" Type="` + espacoRelacoes + `/officeDocument" Target="xl/workbook.xml"/>` +
+        `</Relationships>`
+
+    var livro, relacoesLivro strings.Builder
+    livro.WriteString(cabecalhoXML + `<workbook xmlns="` + espacoPlanilha + `" xmlns:r="` + espacoRelacoes + `"><sheets>`)
+    relacoesLivro.WriteString(cabecalhoXML + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+    for indice := range planilhas {
+        fmt.Fprintf(&livro, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escaparXML(nomes[indice]), indice+1, indice+1)
+        fmt.Fprintf(&relacoesLivro, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, indice+1, espacoRelacoes, indice+1)
+    }
+    livro.WriteString(`</sheets></workbook>`)
+    fmt.Fprintf(&relacoesLivro, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(planilhas)+1, espacoRelacoes)
+    relacoesLivro.WriteString(`</Relationships>`)
+
+    partes := []struct{ caminho, conteudo string }{
+        {"[Content_Types].xml", tipos.String()},
+        {"_rels/.rels", relacoesRaiz},
+        {"xl/workbook.xml", livro.String()},
+        {"xl/_rels/workbook.xml.rels", relacoesLivro.String()},
+        {"xl/styles.xml", estilosXLSX},
+    }
+    for _, parte := range partes {
+        escritor, err := compactador.Create(parte.caminho)
+        if err != nil {
+            return err
+        }
+        if _, err := io.WriteString(escritor, parte.conteudo); err != nil {
+            return err
+        }
+    }
+    for indice, planilha := range planilhas {
+        escritor, err := compactador.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", indice+1))
+        if err != nil {
+            return err
+        }
+        if err := escreverPlanilha(escritor, planilha); err != nil {
+            return err
+        }
+    }
+    return compactador.Close()
+}
+
+func escreverPlanilha(saida io.Writer, planilha Planilha) error {
+    escritor := bufio.NewWriter(saida)
+    escritor.WriteString(cabecalhoXML + `<worksheet xmlns="` + espacoPlanilha + `">`)
+    if len(planilha.Cabecalho) > 0 {
+        escritor.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+    }
+    escritor.WriteString(`<sheetData>`)
+    linhaAtual := 1
+    if len(planilha.Cabecalho) > 0 {
+        fmt.Fprintf(escritor, `<row r="%d">`, linhaAtual)
+        for coluna, titulo := range planilha.Cabecalho {
+            fmt.Fprintf(escritor, `<c r="%s%d" t="inlineStr" s="1"><is><t>%s</t></is></c>`, nomeColuna(coluna), linhaAtual, escaparXML(titulo))
+        }
+        escritor.WriteString(`</row>`)
+        linhaAtual++
+    }
+    for _, linha := range planilha.Linhas {
+        fmt.Fprintf(escritor, `<row r="%d">`, linhaAtual)
+        for coluna, valor := range linha {
+            escreverCelula(escritor, fmt.Sprintf("%s%d", nomeColuna(coluna), linhaAtual), valor)
+        }
+        escritor.WriteString(`</row>`)
+        linhaAtual++
+    }
+    escritor.WriteString(`</sheetData></worksheet>`)
+    return escritor.Flush()
+}
+
+func escreverCelula(escritor *bufio.Writer, referencia string, valor any) {
+    numero := ""
+    switch convertido := valor.(type) {
+    case nil:
+        return
+    case int:
+        numero = strconv.Itoa(convertido)
+    case int64:
+        numero = strconv.FormatInt(convertido, 10)
+    case uint64:
+        numero = strconv.FormatUint(convertido, 10)
+    case float64:
+        if math.IsNaN(convertido) || math.IsInf(convertido, 0) {
+            return
+        }
+        numero = strconv.FormatFloat(convertido, 'g', -1, 64)
+    case bool:
+        indicador := "0"
+        if convertido {
+            indicador = "1"
+        }
+        fmt.Fprintf(escritor, `<c r="%s" t="b"><v>%s</v></c>`, referencia, indicador)
+        return
+    default:
+        fmt.Fprintf(escritor, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, referencia, escaparXML(fmt.Sprint(valor)))
+        return
+    }
+    fmt.Fprintf(escritor, `<c r="%s"><v>%s</v></c>`, referencia, numero)
+}